@@ -6,6 +6,7 @@ require (
 	github.com/google/go-cmp v0.6.0
 	github.com/open-policy-agent/opa v0.65.0
 	github.com/sourcegraph/jsonrpc2 v0.2.0
+	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
@@ -14,5 +15,4 @@ require (
 	github.com/xeipuuv/gojsonpointer v0.0.0-20190905194746-02993c407bfb // indirect
 	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	sigs.k8s.io/yaml v1.4.0 // indirect
 )
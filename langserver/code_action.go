@@ -0,0 +1,43 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func (h *handler) handleTextDocumentCodeAction(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.CodeActionParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	path := documentURIToURI(params.TextDocument.URI)
+	actions := h.project.ListCodeActions(path, params.Range.Start.Line+1, params.Range.End.Line+1)
+
+	uri := string(params.TextDocument.URI)
+	items := make([]lsp.CodeAction, len(actions))
+	for i, a := range actions {
+		edits := make([]lsp.TextEdit, len(a.Edits))
+		for j, e := range a.Edits {
+			edits[j] = lsp.TextEdit{
+				Range:   toLspRange(e.Location),
+				NewText: e.NewText,
+			}
+		}
+		items[i] = lsp.CodeAction{
+			Title:       a.Title,
+			Kind:        lsp.CAKQuickFix,
+			Diagnostics: []lsp.Diagnostic{convertErrorToDiagnostic(a.Error)},
+			IsPreferred: len(actions) == 1,
+			Edit:        &lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{uri: edits}},
+		}
+	}
+	return items, nil
+}
@@ -28,22 +28,60 @@ func (h *handler) handleTextDocumentCompletion(ctx context.Context, conn *jsonrp
 		return nil, err
 	}
 
-	return completionItemToLspCompletionList(items, h.clientSupportSnippets()), nil
+	return completionItemToLspCompletionList(items, h.snippetStyle()), nil
 }
 
-func (h *handler) clientSupportSnippets() bool {
-	return h.initializeParams.Capabilities.TextDocument.Completion.CompletionItem.SnippetSupport
+func (h *handler) handleCompletionItemResolve(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.CompletionItem
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+	if params.Data == nil {
+		return params, nil
+	}
+
+	b, err := json.Marshal(params.Data)
+	if err != nil {
+		return nil, err
+	}
+	var data source.CompletionData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	item := h.project.ResolveCompletionItem(source.CompletionItem{Label: params.Label, Data: &data})
+	params.Detail = item.Detail
+	params.Documentation = createDocumentation(item.Documentation)
+	return params, nil
+}
+
+// snippetStyle returns the snippet style of the completion. When the client doesn't support snippets, it is snippetStyleNone.
+func (h *handler) snippetStyle() string {
+	if !h.initializeParams.Capabilities.TextDocument.Completion.CompletionItem.SnippetSupport {
+		return snippetStyleNone
+	}
+
+	switch style := h.getConfig().CompletionSnippetStyle; style {
+	case snippetStyleBracket, snippetStyleNone:
+		return style
+	default:
+		return snippetStylePlaceholder
+	}
 }
 
-func completionItemToLspCompletionList(items []source.CompletionItem, isSnippetSupport bool) lsp.CompletionList {
+func completionItemToLspCompletionList(items []source.CompletionItem, snippetStyle string) lsp.CompletionList {
 	insertTextFormat := lsp.ITFPlainText
-	if isSnippetSupport {
+	if snippetStyle != snippetStyleNone {
 		insertTextFormat = lsp.ITFSnippet
 	}
 
 	completoinItems := make([]lsp.CompletionItem, len(items))
 	for i, c := range items {
-		completoinItems[i] = createCompletionItem(c, insertTextFormat)
+		completoinItems[i] = createCompletionItem(c, insertTextFormat, snippetStyle)
 	}
 
 	return lsp.CompletionList{
@@ -52,16 +90,7 @@ func completionItemToLspCompletionList(items []source.CompletionItem, isSnippetS
 	}
 }
 
-func createCompletionItem(completionItem source.CompletionItem, insertTextFormat lsp.InsertTextFormat) lsp.CompletionItem {
-	if insertTextFormat == lsp.ITFPlainText {
-		return lsp.CompletionItem{
-			Label:            completionItem.Label,
-			Kind:             kindToLspKind(completionItem.Kind),
-			Detail:           completionItem.Detail,
-			InsertTextFormat: insertTextFormat,
-		}
-	}
-
+func createCompletionItem(completionItem source.CompletionItem, insertTextFormat lsp.InsertTextFormat, snippetStyle string) lsp.CompletionItem {
 	additionalTextEdit := make([]lsp.TextEdit, len(completionItem.AdditionalTextEdits))
 	for i, a := range completionItem.AdditionalTextEdits {
 		additionalTextEdit[i] = createAdditionalTextEdit(a)
@@ -71,10 +100,29 @@ func createCompletionItem(completionItem source.CompletionItem, insertTextFormat
 		Label:               completionItem.Label,
 		Kind:                kindToLspKind(completionItem.Kind),
 		Detail:              completionItem.Detail,
-		InsertTextFormat:    lsp.ITFSnippet,
-		TextEdit:            createTextEdit(completionItem.TextEdit, completionItem.Kind),
+		Documentation:       createDocumentation(completionItem.Documentation),
+		InsertTextFormat:    insertTextFormat,
+		TextEdit:            createTextEdit(completionItem.TextEdit, completionItem.Kind, snippetStyle),
 		AdditionalTextEdits: additionalTextEdit,
+		Data:                createCompletionData(completionItem.Data),
+	}
+}
+
+// createCompletionData returns the data which is sent back by completionItem/resolve.
+// It returns nil when the completion item is not resolved lazily.
+func createCompletionData(data *source.CompletionData) any {
+	if data == nil {
+		return nil
+	}
+	return data
+}
+
+// createDocumentation returns the markdown documentation. It returns nil for the empty document.
+func createDocumentation(doc string) *lsp.MarkupContent {
+	if doc == "" {
+		return nil
 	}
+	return &lsp.MarkupContent{Kind: lsp.MKMarkdown, Value: doc}
 }
 
 func kindToLspKind(kind source.CompletionKind) lsp.CompletionItemKind {
@@ -85,6 +133,8 @@ func kindToLspKind(kind source.CompletionKind) lsp.CompletionItemKind {
 		return lsp.CIKModule
 	case source.FunctionItem, source.BuiltinFunctionItem:
 		return lsp.CIKFunction
+	case source.FieldItem:
+		return lsp.CIKField
 	default:
 		return lsp.CIKText
 	}
@@ -106,7 +156,7 @@ func createAdditionalTextEdit(textEdit source.TextEdit) lsp.TextEdit {
 	}
 }
 
-func createTextEdit(textEdit *source.TextEdit, kind source.CompletionKind) *lsp.TextEdit {
+func createTextEdit(textEdit *source.TextEdit, kind source.CompletionKind, snippetStyle string) *lsp.TextEdit {
 	if textEdit == nil {
 		return nil
 	}
@@ -121,13 +171,22 @@ func createTextEdit(textEdit *source.TextEdit, kind source.CompletionKind) *lsp.
 				Character: textEdit.Col - 1 + len(textEdit.Text),
 			},
 		},
-		NewText: createSnippetText(textEdit.Text, kind),
+		NewText: createSnippetText(textEdit.Text, kind, snippetStyle),
 	}
 }
 
-func createSnippetText(insertText string, kind source.CompletionKind) string {
+// createSnippetText returns the text with the snippet placeholders. snippetStyleNone returns the plain text.
+func createSnippetText(insertText string, kind source.CompletionKind, snippetStyle string) string {
+	if snippetStyle == snippetStyleNone {
+		return insertText
+	}
+
 	switch kind {
 	case source.FunctionItem, source.BuiltinFunctionItem:
+		if snippetStyle == snippetStyleBracket {
+			return addBracketSnippet(insertText)
+		}
+
 		if i := strings.Index(insertText, "("); i >= 0 {
 			return addFunctionSnippet(insertText, "(", ")")
 		}
@@ -178,3 +237,15 @@ func addFunctionSnippet(insertText string, lbracket string, rbracket string) str
 	}
 	return insertText
 }
+
+// addBracketSnippet drops the arguments and puts the cursor in the brackets.
+//
+//	f(x, y) -> f($1)
+func addBracketSnippet(insertText string) string {
+	for _, b := range []struct{ l, r string }{{"(", ")"}, {"[", "]"}} {
+		if i := strings.Index(insertText, b.l); i >= 0 {
+			return insertText[:i] + b.l + "$1" + b.r
+		}
+	}
+	return insertText
+}
@@ -10,8 +10,8 @@ import (
 
 func TestCompletionItemToLspCompletionList(t *testing.T) {
 	tests := map[string]struct {
-		items            []source.CompletionItem
-		isSnippetSupport bool
+		items        []source.CompletionItem
+		snippetStyle string
 
 		expectCompletionList lsp.CompletionList
 	}{
@@ -69,7 +69,7 @@ func TestCompletionItemToLspCompletionList(t *testing.T) {
 					},
 				},
 			},
-			isSnippetSupport: true,
+			snippetStyle: snippetStylePlaceholder,
 			expectCompletionList: lsp.CompletionList{
 				IsIncomplete: false,
 				Items: []lsp.CompletionItem{
@@ -188,8 +188,24 @@ func TestCompletionItemToLspCompletionList(t *testing.T) {
 						Text: "method(a, b)",
 					},
 				},
+				{
+					Label: "lib.is_hello",
+					Kind:  source.FunctionItem,
+					TextEdit: &source.TextEdit{
+						Row:  5,
+						Col:  2,
+						Text: "lib.is_hello(msg)",
+					},
+					AdditionalTextEdits: []source.TextEdit{
+						{
+							Row:  3,
+							Col:  1,
+							Text: "import data.lib\n",
+						},
+					},
+				},
 			},
-			isSnippetSupport: false,
+			snippetStyle: snippetStyleNone,
 			expectCompletionList: lsp.CompletionList{
 				IsIncomplete: false,
 				Items: []lsp.CompletionItem{
@@ -198,6 +214,163 @@ func TestCompletionItemToLspCompletionList(t *testing.T) {
 						Kind:             lsp.CIKFunction,
 						Detail:           "detail",
 						InsertTextFormat: lsp.ITFPlainText,
+						TextEdit: &lsp.TextEdit{
+							Range: lsp.Range{
+								Start: lsp.Position{
+									Line:      0,
+									Character: 0,
+								},
+								End: lsp.Position{
+									Line:      0,
+									Character: len("method(a, b)"),
+								},
+							},
+							NewText: "method(a, b)",
+						},
+						AdditionalTextEdits: []lsp.TextEdit{},
+					},
+					{
+						Label:            "lib.is_hello",
+						Kind:             lsp.CIKFunction,
+						InsertTextFormat: lsp.ITFPlainText,
+						TextEdit: &lsp.TextEdit{
+							Range: lsp.Range{
+								Start: lsp.Position{
+									Line:      4,
+									Character: 1,
+								},
+								End: lsp.Position{
+									Line:      4,
+									Character: 1 + len("lib.is_hello(msg)"),
+								},
+							},
+							NewText: "lib.is_hello(msg)",
+						},
+						AdditionalTextEdits: []lsp.TextEdit{
+							{
+								Range: lsp.Range{
+									Start: lsp.Position{
+										Line:      2,
+										Character: 0,
+									},
+									End: lsp.Position{
+										Line:      2,
+										Character: 0,
+									},
+								},
+								NewText: "import data.lib\n",
+							},
+						},
+					},
+				},
+			},
+		},
+		"item resolved lazily": {
+			items: []source.CompletionItem{
+				{
+					Label: "is_hello",
+					Kind:  source.FunctionItem,
+					TextEdit: &source.TextEdit{
+						Row:  1,
+						Col:  1,
+						Text: "is_hello(msg)",
+					},
+					Data: &source.CompletionData{Package: "data.lib", Name: "is_hello"},
+				},
+			},
+			snippetStyle: snippetStyleNone,
+			expectCompletionList: lsp.CompletionList{
+				IsIncomplete: false,
+				Items: []lsp.CompletionItem{
+					{
+						Label:            "is_hello",
+						Kind:             lsp.CIKFunction,
+						InsertTextFormat: lsp.ITFPlainText,
+						TextEdit: &lsp.TextEdit{
+							Range: lsp.Range{
+								Start: lsp.Position{
+									Line:      0,
+									Character: 0,
+								},
+								End: lsp.Position{
+									Line:      0,
+									Character: len("is_hello(msg)"),
+								},
+							},
+							NewText: "is_hello(msg)",
+						},
+						AdditionalTextEdits: []lsp.TextEdit{},
+						Data:                &source.CompletionData{Package: "data.lib", Name: "is_hello"},
+					},
+				},
+			},
+		},
+		"bracket snippet style": {
+			items: []source.CompletionItem{
+				{
+					Label:  "method",
+					Kind:   source.FunctionItem,
+					Detail: "detail",
+					TextEdit: &source.TextEdit{
+						Row:  1,
+						Col:  1,
+						Text: "method(a, b)",
+					},
+				},
+				{
+					Label:  "mes",
+					Kind:   source.FunctionItem,
+					Detail: "detail",
+					TextEdit: &source.TextEdit{
+						Row:  1,
+						Col:  1,
+						Text: "mes[a]",
+					},
+				},
+			},
+			snippetStyle: snippetStyleBracket,
+			expectCompletionList: lsp.CompletionList{
+				IsIncomplete: false,
+				Items: []lsp.CompletionItem{
+					{
+						Label:            "method",
+						Kind:             lsp.CIKFunction,
+						Detail:           "detail",
+						InsertTextFormat: lsp.ITFSnippet,
+						TextEdit: &lsp.TextEdit{
+							Range: lsp.Range{
+								Start: lsp.Position{
+									Line:      0,
+									Character: 0,
+								},
+								End: lsp.Position{
+									Line:      0,
+									Character: len("method(a, b)"),
+								},
+							},
+							NewText: "method($1)",
+						},
+						AdditionalTextEdits: []lsp.TextEdit{},
+					},
+					{
+						Label:            "mes",
+						Kind:             lsp.CIKFunction,
+						Detail:           "detail",
+						InsertTextFormat: lsp.ITFSnippet,
+						TextEdit: &lsp.TextEdit{
+							Range: lsp.Range{
+								Start: lsp.Position{
+									Line:      0,
+									Character: 0,
+								},
+								End: lsp.Position{
+									Line:      0,
+									Character: len("mes[a]"),
+								},
+							},
+							NewText: "mes[$1]",
+						},
+						AdditionalTextEdits: []lsp.TextEdit{},
 					},
 				},
 			},
@@ -206,7 +379,7 @@ func TestCompletionItemToLspCompletionList(t *testing.T) {
 
 	for n, tt := range tests {
 		t.Run(n, func(t *testing.T) {
-			got := completionItemToLspCompletionList(tt.items, tt.isSnippetSupport)
+			got := completionItemToLspCompletionList(tt.items, tt.snippetStyle)
 			if diff := cmp.Diff(tt.expectCompletionList, got); diff != "" {
 				t.Errorf("completionItemToLspCompletionList result diff (-expect, +got)\n%s", diff)
 			}
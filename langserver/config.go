@@ -0,0 +1,219 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// configSection is the section of the settings in workspace/configuration and workspace/didChangeConfiguration.
+const configSection = "regols"
+
+const (
+	diagnosticsOnChange = "onChange"
+	diagnosticsOnSave   = "onSave"
+)
+
+const (
+	snippetStylePlaceholder = "placeholder"
+	snippetStyleBracket     = "bracket"
+	snippetStyleNone        = "none"
+)
+
+// config is the settings of the server which the client sends in initializationOptions
+// or in the "regols" section of the client configuration.
+//
+//	{
+//	  "regoVersion": "v1",
+//	  "regoVersionByDirectory": {"legacy": "v0"},
+//	  "strict": false,
+//	  "lint": {"unused-import": "warning"},
+//	  "diagnosticsTrigger": "onChange",
+//	  "completionSnippetStyle": "placeholder"
+//	}
+type config struct {
+	// RegoVersion is the default rego version. "auto", "v0", "v0-future-keywords" or "v1".
+	RegoVersion string `json:"regoVersion,omitempty"`
+	// RegoVersionByDirectory is the rego version for each directory.
+	// The relative directory is resolved from each workspace folder.
+	RegoVersionByDirectory map[string]string `json:"regoVersionByDirectory,omitempty"`
+	// Strict reports the errors of the compiler's strict mode.
+	Strict bool `json:"strict,omitempty"`
+	// Lint is the severity of each lint check. "error", "warning", "information", "hint" or "off".
+	Lint map[string]string `json:"lint,omitempty"`
+	// DiagnosticsTrigger is when the diagnostics run. "onChange" (default) or "onSave".
+	DiagnosticsTrigger string `json:"diagnosticsTrigger,omitempty"`
+	// CompletionSnippetStyle is the style of the function arguments in the completion.
+	// "placeholder" (default) inserts the arguments as placeholders, "bracket" inserts only the brackets
+	// and "none" doesn't use snippets.
+	CompletionSnippetStyle string `json:"completionSnippetStyle,omitempty"`
+}
+
+func parseConfig(options any) (config, error) {
+	var c config
+	if options == nil {
+		return c, nil
+	}
+
+	b, err := json.Marshal(options)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// getConfig returns the current settings.
+func (h *handler) getConfig() config {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.config
+}
+
+// applyConfig applies the config to the project.
+func (h *handler) applyConfig(c config) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	version, err := source.ParseRegoVersion(c.RegoVersion)
+	if err != nil {
+		return err
+	}
+
+	severities := make(map[string]source.LintSeverity, len(c.Lint))
+	for check, s := range c.Lint {
+		severity, err := source.ParseLintSeverity(s)
+		if err != nil {
+			return err
+		}
+		severities[check] = severity
+	}
+
+	versions := make(map[string]source.RegoVersion, len(c.RegoVersionByDirectory))
+	for dir, v := range c.RegoVersionByDirectory {
+		version, err := source.ParseRegoVersion(v)
+		if err != nil {
+			return err
+		}
+		versions[dir] = version
+	}
+
+	// reset the directories of the previous config. The workspace folders restore the version of .regols.yaml.
+	for dir := range h.config.RegoVersionByDirectory {
+		for _, d := range h.resolveDir(dir) {
+			if err := h.project.ResetRegoVersion(d); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := h.project.SetRegoVersion("", version); err != nil {
+		return err
+	}
+
+	for dir, version := range versions {
+		for _, d := range h.resolveDir(dir) {
+			if err := h.project.SetRegoVersion(d, version); err != nil {
+				return err
+			}
+		}
+	}
+
+	h.project.SetLintOptions(source.LintOptions{Strict: c.Strict, Severities: severities})
+	h.config = c
+	return nil
+}
+
+// showConfigError logs the error of the configuration files and shows it in the client.
+// It returns false when err is not the source.ConfigError.
+func (h *handler) showConfigError(ctx context.Context, err error) bool {
+	var configErr *source.ConfigError
+	if !errors.As(err, &configErr) {
+		return false
+	}
+
+	h.logger.Printf("%v", err)
+	h.conn.Notify(ctx, "window/showMessage", lsp.ShowMessageParams{
+		Type:    lsp.MTError,
+		Message: err.Error(),
+	})
+	return true
+}
+
+// resolveDir resolves the relative directory from each workspace folder.
+func (h *handler) resolveDir(dir string) []string {
+	if filepath.IsAbs(dir) {
+		return []string{dir}
+	}
+
+	result := make([]string, 0)
+	for _, r := range h.project.RootPaths() {
+		result = append(result, filepath.Join(r, dir))
+	}
+	return result
+}
+
+// fetchConfig requests the settings with workspace/configuration, and then applies them.
+// This should be called in a goroutine, because the handler cannot receive the response until the request handling finishes.
+func (h *handler) fetchConfig(ctx context.Context, conn *jsonrpc2.Conn) {
+	if !h.initializeParams.Capabilities.Workspace.Configuration {
+		return
+	}
+
+	params := lsp.ConfigurationParams{
+		Items: []lsp.ConfigurationItem{{Section: configSection}},
+	}
+	var result lsp.ConfigurationResult
+	if err := conn.Call(ctx, "workspace/configuration", params, &result); err != nil {
+		h.logger.Printf("failed to get configuration: %v", err)
+		return
+	}
+	if len(result) == 0 || result[0] == nil {
+		return
+	}
+
+	h.updateConfig(result[0])
+}
+
+// updateConfig applies the settings and runs the diagnostics again.
+func (h *handler) updateConfig(settings any) {
+	c, err := parseConfig(settings)
+	if err != nil {
+		h.logger.Printf("failed to parse configuration: %v", err)
+		return
+	}
+	if err := h.applyConfig(c); err != nil {
+		h.logger.Printf("failed to apply configuration: %v", err)
+		return
+	}
+
+	for _, r := range h.project.RootPaths() {
+		h.diagnosticRequest <- uriToDocumentURI(r)
+	}
+}
+
+func (h *handler) handleWorkspaceDidChangeConfiguration(_ context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.DidChangeConfigurationParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	m, ok := params.Settings.(map[string]any)
+	if section, found := m[configSection]; ok && found {
+		h.updateConfig(section)
+		return nil, nil
+	}
+
+	// The client which supports workspace/configuration may send null settings.
+	go h.fetchConfig(context.Background(), conn)
+	return nil, nil
+}
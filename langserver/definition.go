@@ -96,3 +96,21 @@ func toLspLocation(location *ast.Location, rawText string) lsp.Location {
 		},
 	}
 }
+
+// toLspRange converts the location to the range whose end is exclusive.
+func toLspRange(location *ast.Location) lsp.Range {
+	start := lsp.Position{
+		Line:      location.Row - 1,
+		Character: location.Col - 1,
+	}
+
+	end := start
+	text := string(location.Text)
+	if ind := strings.LastIndex(text, "\n"); ind >= 0 {
+		end.Line += strings.Count(text, "\n")
+		end.Character = len(text) - ind - 1
+	} else {
+		end.Character += len(text)
+	}
+	return lsp.Range{Start: start, End: end}
+}
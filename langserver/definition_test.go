@@ -58,3 +58,42 @@ world`,
 		})
 	}
 }
+
+func TestToLspRange(t *testing.T) {
+	tests := map[string]struct {
+		location *ast.Location
+		expect   lsp.Range
+	}{
+		"single line": {
+			location: &ast.Location{
+				Row:  2,
+				Col:  3,
+				Text: []byte("hello"),
+			},
+			expect: lsp.Range{
+				Start: lsp.Position{Line: 1, Character: 2},
+				End:   lsp.Position{Line: 1, Character: 7},
+			},
+		},
+		"multi line": {
+			location: &ast.Location{
+				Row:  2,
+				Col:  3,
+				Text: []byte("hello {\n\ttrue\n}"),
+			},
+			expect: lsp.Range{
+				Start: lsp.Position{Line: 1, Character: 2},
+				End:   lsp.Position{Line: 3, Character: 1},
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			got := toLspRange(tt.location)
+			if diff := cmp.Diff(tt.expect, got); diff != "" {
+				t.Errorf("toLspRange result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
 	"github.com/open-policy-agent/opa/ast"
 )
 
@@ -25,17 +26,32 @@ func (h *handler) diagnostic() {
 				h.logger.Println(err)
 				return
 			}
-
-			for uri, d := range diagnostics {
-				h.conn.Notify(ctx, "textDocument/publishDiagnostics", lsp.PublishDiagnosticsParams{
-					URI:         uri,
-					Diagnostics: d,
-				})
-			}
+			h.publishDiagnostics(ctx, diagnostics)
 		}()
 	}
 }
 
+// diagnoseDependents runs the diagnostics for the files which depend on the closed document.
+// The diagnostics of the closed document itself are cleared instead of being published again.
+func (h *handler) diagnoseDependents(ctx context.Context, closed lsp.DocumentURI) {
+	diagnostics, err := h.diagnose(closed)
+	if err != nil {
+		h.logger.Println(err)
+		return
+	}
+	diagnostics[closed] = []lsp.Diagnostic{}
+	h.publishDiagnostics(ctx, diagnostics)
+}
+
+func (h *handler) publishDiagnostics(ctx context.Context, diagnostics map[lsp.DocumentURI][]lsp.Diagnostic) {
+	for uri, d := range diagnostics {
+		h.conn.Notify(ctx, "textDocument/publishDiagnostics", lsp.PublishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: d,
+		})
+	}
+}
+
 func (h *handler) diagnose(uri lsp.DocumentURI) (map[lsp.DocumentURI][]lsp.Diagnostic, error) {
 	result := make(map[lsp.DocumentURI][]lsp.Diagnostic)
 
@@ -45,9 +61,38 @@ func (h *handler) diagnose(uri lsp.DocumentURI) (map[lsp.DocumentURI][]lsp.Diagn
 		result[uri] = convertErrorsToDiagnostics(errs)
 	}
 
+	for path, issues := range h.project.Lint(pathToErrs) {
+		uri := uriToDocumentURI(path)
+		result[uri] = append(result[uri], convertLintIssuesToDiagnostics(issues)...)
+	}
+
 	return result, nil
 }
 
+func convertLintIssuesToDiagnostics(issues []source.LintIssue) []lsp.Diagnostic {
+	result := make([]lsp.Diagnostic, len(issues))
+	for i, issue := range issues {
+		d := convertErrorToDiagnostic(issue.Error)
+		d.Severity = lintSeverityToDiagnosticSeverity(issue.Severity)
+		d.Code = issue.Check
+		result[i] = d
+	}
+	return result
+}
+
+func lintSeverityToDiagnosticSeverity(s source.LintSeverity) lsp.DiagnosticSeverity {
+	switch s {
+	case source.LintError:
+		return lsp.Error
+	case source.LintWarning:
+		return lsp.Warning
+	case source.LintInformation:
+		return lsp.Information
+	default:
+		return lsp.Hint
+	}
+}
+
 func convertErrorsToDiagnostics(errs ast.Errors) []lsp.Diagnostic {
 	result := make([]lsp.Diagnostic, len(errs))
 	for i, e := range errs {
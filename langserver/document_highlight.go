@@ -0,0 +1,41 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func (h *handler) handleTextDocumentDocumentHighlight(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.TextDocumentPositionParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	loc := h.toOPALocation(params.Position, params.TextDocument.URI)
+	highlights, err := h.project.DocumentHighlights(loc)
+	if err != nil {
+		h.logger.Printf("failed to get document highlights: %v", err)
+		return nil, nil
+	}
+
+	items := make([]lsp.DocumentHighlight, len(highlights))
+	for i, hl := range highlights {
+		kind := lsp.Read
+		if hl.Kind == source.WriteHighlight {
+			kind = lsp.Write
+		}
+		items[i] = lsp.DocumentHighlight{
+			Range: toLspRange(hl.Location),
+			Kind:  kind,
+		}
+	}
+	return items, nil
+}
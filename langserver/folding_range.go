@@ -0,0 +1,43 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func (h *handler) handleTextDocumentFoldingRange(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.FoldingRangeParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	ranges := h.project.ListFoldingRanges(documentURIToURI(params.TextDocument.URI))
+	items := make([]lsp.FoldingRange, len(ranges))
+	for i, r := range ranges {
+		items[i] = lsp.FoldingRange{
+			StartLine: r.StartRow - 1,
+			EndLine:   r.EndRow - 1,
+			Kind:      foldingRangeKindToLspKind(r.Kind),
+		}
+	}
+	return items, nil
+}
+
+func foldingRangeKindToLspKind(kind source.FoldingRangeKind) lsp.FoldingRangeKind {
+	switch kind {
+	case source.CommentFolding:
+		return lsp.FRKComment
+	case source.ImportsFolding:
+		return lsp.FRKImports
+	default:
+		return lsp.FRKRegion
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/kitagry/regols/langserver/internal/lsp"
-	"github.com/open-policy-agent/opa/format"
 	"github.com/sourcegraph/jsonrpc2"
 )
 
@@ -26,9 +25,10 @@ func (h *handler) handleTextDocumentFormatting(_ context.Context, _ *jsonrpc2.Co
 		return nil, fmt.Errorf("failed to find document %s", params.TextDocument.URI)
 	}
 
-	formatted, err := format.Source(documentURIToURI(params.TextDocument.URI), []byte(rawText))
-	if !ok {
-		return nil, fmt.Errorf("failed to format: %w", err)
+	formatted, err := h.project.Format(documentURIToURI(params.TextDocument.URI))
+	if err != nil {
+		h.logger.Printf("failed to format: %v", err)
+		return nil, nil
 	}
 
 	if len(formatted) == 0 {
@@ -30,6 +30,11 @@ func (h *handler) documentIdent(_ context.Context, uri lsp.DocumentURI, position
 
 	result := make([]lsp.MarkedString, len(documentResults))
 	for i, d := range documentResults {
+		// The raw string is rendered as markdown.
+		if d.Language == "markdown" {
+			result[i] = lsp.RawMarkedString(d.Content)
+			continue
+		}
 		result[i] = lsp.MarkedString{Language: d.Language, Value: d.Content}
 	}
 	return lsp.Hover{Contents: result}, nil
@@ -22,21 +22,54 @@ func (h *handler) handleInitialize(_ context.Context, conn *jsonrpc2.Conn, req *
 	}
 	h.initializeParams = params
 
-	p, err := source.NewProject(params.RootPath)
-	if err != nil {
+	p, err := source.NewProject(rootPaths(params)...)
+	if err != nil && p == nil {
 		return nil, err
 	}
 	h.project = p
+	if err != nil {
+		// The invalid configuration files fall back to the default, so the server can start.
+		go h.showConfigError(context.Background(), err)
+	}
+
+	c, err := parseConfig(params.InitializationOptions)
+	if err != nil {
+		h.logger.Printf("failed to parse initializationOptions: %v", err)
+		c = config{}
+	}
+	if err := h.applyConfig(c); err != nil {
+		h.logger.Printf("failed to apply initializationOptions: %v", err)
+		if err := h.applyConfig(config{}); err != nil {
+			return nil, err
+		}
+	}
 
 	return lsp.InitializeResult{
 		Capabilities: lsp.ServerCapabilities{
 			TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
-				Kind: toPtr(lsp.TDSKFull),
+				Kind: toPtr(lsp.TDSKIncremental),
 			},
 			DocumentFormattingProvider: true,
 			DefinitionProvider:         true,
 			HoverProvider:              true,
 			ReferencesProvider:         true,
+			DocumentHighlightProvider:  true,
+			RenameProvider:             h.renameProvider(),
+			DocumentSymbolProvider:     true,
+			WorkspaceSymbolProvider:    true,
+			CodeActionProvider:         true,
+			SemanticTokensProvider:     semanticTokensProvider(),
+			FoldingRangeProvider:       true,
+			SignatureHelpProvider: &lsp.SignatureHelpOptions{
+				TriggerCharacters: []string{"(", ","},
+			},
+			Workspace: &lsp.WorkspaceServerCapabilities{
+				WorkspaceFolders: &lsp.WorkspaceFoldersServerCapabilities{
+					Supported:           true,
+					ChangeNotifications: true,
+				},
+				FileOperations: fileOperationsCapabilities(),
+			},
 			CompletionProvider: &lsp.CompletionOptions{
 				TriggerCharacters: []string{"*", "."},
 				ResolveProvider:   true,
@@ -45,6 +78,35 @@ func (h *handler) handleInitialize(_ context.Context, conn *jsonrpc2.Conn, req *
 	}, nil
 }
 
+// rootPaths returns the paths of the workspace folders.
+// When the client doesn't support workspace folders, rootUri or rootPath is used.
+func rootPaths(params lsp.InitializeParams) []string {
+	if len(params.WorkspaceFolders) > 0 {
+		result := make([]string, len(params.WorkspaceFolders))
+		for i, f := range params.WorkspaceFolders {
+			result[i] = documentURIToURI(f.URI)
+		}
+		return result
+	}
+
+	if params.RootURI != "" {
+		return []string{documentURIToURI(params.RootURI)}
+	}
+
+	if params.RootPath != "" {
+		return []string{params.RootPath}
+	}
+	return nil
+}
+
+func (h *handler) renameProvider() any {
+	rename := h.initializeParams.Capabilities.TextDocument.Rename
+	if rename != nil && rename.PrepareSupport {
+		return lsp.RenameOptions{PrepareProvider: true}
+	}
+	return true
+}
+
 func toPtr[T any](t T) *T {
 	return &t
 }
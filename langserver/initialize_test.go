@@ -0,0 +1,52 @@
+package langserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/lsp"
+)
+
+func TestRootPaths(t *testing.T) {
+	tests := map[string]struct {
+		params lsp.InitializeParams
+		expect []string
+	}{
+		"workspace folders": {
+			params: lsp.InitializeParams{
+				RootURI: "file:///root",
+				WorkspaceFolders: []lsp.WorkspaceFolder{
+					{URI: "file:///root/policies", Name: "policies"},
+					{URI: "file:///root/lib", Name: "lib"},
+				},
+			},
+			expect: []string{"/root/policies", "/root/lib"},
+		},
+		"root uri": {
+			params: lsp.InitializeParams{
+				RootURI:  "file:///root",
+				RootPath: "/deprecated",
+			},
+			expect: []string{"/root"},
+		},
+		"root path": {
+			params: lsp.InitializeParams{
+				RootPath: "/root",
+			},
+			expect: []string{"/root"},
+		},
+		"no root": {
+			params: lsp.InitializeParams{},
+			expect: nil,
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			got := rootPaths(tt.params)
+			if diff := cmp.Diff(tt.expect, got); diff != "" {
+				t.Errorf("rootPaths result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -13,26 +13,53 @@ import (
 
 type Policy struct {
 	RawText string
+	Version int
 	Errs    ast.Errors
 	Module  *ast.Module
 }
 
+// GlobalCache holds the policies on the disk and the policies which are opened in the client.
+// The opened policies shadow the policies on the disk.
 type GlobalCache struct {
 	mu            sync.RWMutex
 	pathToPlicies map[string]*Policy
+	pathToOverlay map[string]*Policy
+	// regoVersions is the rego version for each directory. "." is the default version.
+	regoVersions map[string]RegoVersion
+	// ignorePatterns is the configured patterns of the ignored paths for each root directory.
+	ignorePatterns map[string][]ignorePattern
+	// ignoreFiles is the patterns of the ignore files for each directory.
+	ignoreFiles  map[string][]ignorePattern
+	capabilities *ast.Capabilities
+	// pathToData is the JSON and YAML data documents.
+	pathToData map[string]*DataDocument
+	// dataRoots is the root directories of the data documents for each root directory.
+	dataRoots map[string][]string
+	// schemaSet is the schemas which are used to type check the policies.
+	schemaSet *ast.SchemaSet
+	// inputSchemas is the configured input schemas for each root directory.
+	inputSchemas map[string][]inputSchema
+	// compileErrs is the errors of the last compile which is not strict. It is valid while compiled is true.
+	compileErrs ast.Errors
+	compiled    bool
+	// compileMu guards compileErrs, because the readers which hold the read lock store it.
+	compileMu sync.Mutex
 }
 
-func NewGlobalCache(rootPath string) (*GlobalCache, error) {
-	g := &GlobalCache{pathToPlicies: make(map[string]*Policy)}
-
-	regoFilePaths, err := loadRegoFiles(rootPath)
-	if err != nil {
-		return nil, err
+func NewGlobalCache(rootPaths ...string) (*GlobalCache, error) {
+	g := &GlobalCache{
+		pathToPlicies:  make(map[string]*Policy),
+		pathToOverlay:  make(map[string]*Policy),
+		regoVersions:   make(map[string]RegoVersion),
+		ignorePatterns: make(map[string][]ignorePattern),
+		ignoreFiles:    make(map[string][]ignorePattern),
+		pathToData:     make(map[string]*DataDocument),
+		dataRoots:      make(map[string][]string),
+		inputSchemas:   make(map[string][]inputSchema),
 	}
 
-	for _, path := range regoFilePaths {
-		err = g.putWithPath(path)
-		if err != nil {
+	for _, rootPath := range rootPaths {
+		if err := g.LoadDir(rootPath); err != nil {
 			return nil, err
 		}
 	}
@@ -40,7 +67,16 @@ func NewGlobalCache(rootPath string) (*GlobalCache, error) {
 }
 
 func NewGlobalCacheWithFiles(pathToText map[string]string) (*GlobalCache, error) {
-	g := &GlobalCache{pathToPlicies: make(map[string]*Policy, len(pathToText))}
+	g := &GlobalCache{
+		pathToPlicies:  make(map[string]*Policy, len(pathToText)),
+		pathToOverlay:  make(map[string]*Policy),
+		regoVersions:   make(map[string]RegoVersion),
+		ignorePatterns: make(map[string][]ignorePattern),
+		ignoreFiles:    make(map[string][]ignorePattern),
+		pathToData:     make(map[string]*DataDocument),
+		dataRoots:      make(map[string][]string),
+		inputSchemas:   make(map[string][]inputSchema),
+	}
 
 	for path, text := range pathToText {
 		err := g.Put(path, text)
@@ -51,18 +87,28 @@ func NewGlobalCacheWithFiles(pathToText map[string]string) (*GlobalCache, error)
 	return g, nil
 }
 
-func loadRegoFiles(rootPath string) ([]string, error) {
+// loadFiles lists the rego files and the data documents under the root path.
+func (g *GlobalCache) loadFiles(rootPath string) ([]string, error) {
 	result := make([]string, 0)
 	err := filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
+		if g.IsIgnored(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if d.IsDir() {
+			// The ignore files are applied to the files under the directory.
+			g.loadIgnoreFiles(path)
 			return nil
 		}
 
-		if strings.HasSuffix(d.Name(), ".rego") {
+		if strings.HasSuffix(d.Name(), ".rego") || IsDataFile(path) {
 			result = append(result, path)
 		}
 		return nil
@@ -73,10 +119,58 @@ func loadRegoFiles(rootPath string) ([]string, error) {
 func (g *GlobalCache) Get(path string) *Policy {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
+	return g.get(path)
+}
+
+func (g *GlobalCache) get(path string) *Policy {
+	if p, ok := g.pathToOverlay[path]; ok {
+		return p
+	}
 	return g.pathToPlicies[path]
 }
 
-func (g *GlobalCache) putWithPath(path string) error {
+// policies returns the policies which the opened policies shadow. The caller should hold the lock.
+func (g *GlobalCache) policies() map[string]*Policy {
+	result := make(map[string]*Policy, len(g.pathToPlicies)+len(g.pathToOverlay))
+	for path, p := range g.pathToPlicies {
+		result[path] = p
+	}
+	for path, p := range g.pathToOverlay {
+		result[path] = p
+	}
+	return result
+}
+
+// IsOpen returns true when the policy is opened in the client.
+func (g *GlobalCache) IsOpen(path string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.pathToOverlay[path]
+	return ok
+}
+
+// LoadDir reads all rego files and data documents under the directory.
+func (g *GlobalCache) LoadDir(dir string) error {
+	paths, err := g.loadFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := g.Load(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads the file from the disk and puts it. The ignored file is removed from the disk policies.
+func (g *GlobalCache) Load(path string) error {
+	if g.IsIgnored(path, false) {
+		g.Delete(path)
+		return nil
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return err
@@ -89,40 +183,99 @@ func (g *GlobalCache) putWithPath(path string) error {
 		return err
 	}
 
+	if IsDataFile(path) {
+		g.PutData(path, buf.String())
+		return nil
+	}
 	return g.Put(path, buf.String())
 }
 
+// Put puts the policy on the disk.
 func (g *GlobalCache) Put(path string, rawText string) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	policy, ok := g.pathToPlicies[path]
+	return g.put(g.pathToPlicies, path, rawText, 0)
+}
+
+// PutOverlay puts the policy which is opened in the client with the document version.
+func (g *GlobalCache) PutOverlay(path string, rawText string, version int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// When the opened policy cannot be parsed, the module on the disk is used.
+	if _, ok := g.pathToOverlay[path]; !ok {
+		if p, ok := g.pathToPlicies[path]; ok {
+			g.pathToOverlay[path] = &Policy{Module: p.Module}
+		}
+	}
+	return g.put(g.pathToOverlay, path, rawText, version)
+}
+
+// put parses the policy with the rego version of the path. The caller should hold the lock.
+func (g *GlobalCache) put(pathToPolicies map[string]*Policy, path string, rawText string, version int) error {
+	g.invalidateCompileErrors()
+	policy, ok := pathToPolicies[path]
 	if !ok {
 		policy = &Policy{}
 	}
 	policy.RawText = rawText
-	module, err := ast.ParseModule(path, rawText)
+	policy.Version = version
+	module, err := ast.ParseModuleWithOpts(path, rawText, g.regoVersion(path).parserOptions(rawText))
 	if errs, ok := err.(ast.Errors); ok {
 		policy.Errs = errs
-		g.pathToPlicies[path] = policy
+		pathToPolicies[path] = policy
 		return nil
 	} else if errs, ok := err.(*ast.Error); ok {
 		policy.Errs = ast.Errors{errs}
-		g.pathToPlicies[path] = policy
+		pathToPolicies[path] = policy
 		return nil
 	} else if err != nil {
 		return err
 	}
 	policy.Module = module
 	policy.Errs = nil
-	g.pathToPlicies[path] = policy
+	pathToPolicies[path] = policy
 	return nil
 }
 
+// Delete deletes the policy or the data document on the disk. The opened policy is kept until it is closed.
 func (g *GlobalCache) Delete(path string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	g.invalidateCompileErrors()
 	delete(g.pathToPlicies, path)
+	delete(g.pathToData, path)
+}
+
+// DeleteOverlay deletes the policy which is opened in the client.
+func (g *GlobalCache) DeleteOverlay(path string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invalidateCompileErrors()
+	delete(g.pathToOverlay, path)
+}
+
+// DeleteDir deletes all policies and data documents under the directory and returns the deleted paths.
+func (g *GlobalCache) DeleteDir(dir string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invalidateCompileErrors()
+
+	prefix := strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+	result := make([]string, 0)
+	for path := range g.pathToPlicies {
+		if strings.HasPrefix(path, prefix) {
+			delete(g.pathToPlicies, path)
+			result = append(result, path)
+		}
+	}
+	for path := range g.pathToData {
+		if strings.HasPrefix(path, prefix) {
+			delete(g.pathToData, path)
+		}
+	}
+	return result
 }
 
 func (g *GlobalCache) FindPolicies(packageName ast.Ref) []*ast.Module {
@@ -130,7 +283,7 @@ func (g *GlobalCache) FindPolicies(packageName ast.Ref) []*ast.Module {
 	defer g.mu.RUnlock()
 
 	result := make([]*ast.Module, 0)
-	for _, p := range g.pathToPlicies {
+	for _, p := range g.policies() {
 		if p.Module != nil && p.Module.Package.Path.Equal(packageName) {
 			result = append(result, p.Module)
 		}
@@ -138,6 +291,20 @@ func (g *GlobalCache) FindPolicies(packageName ast.Ref) []*ast.Module {
 	return result
 }
 
+func (g *GlobalCache) GetModules() []*ast.Module {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	policies := g.policies()
+	result := make([]*ast.Module, 0, len(policies))
+	for _, p := range policies {
+		if p.Module != nil {
+			result = append(result, p.Module)
+		}
+	}
+	return result
+}
+
 func (g *GlobalCache) GetErrors(path string) map[string]ast.Errors {
 	// parse error
 	if p := g.Get(path); p != nil && len(p.Errs) != 0 {
@@ -148,33 +315,87 @@ func (g *GlobalCache) GetErrors(path string) map[string]ast.Errors {
 	defer g.mu.RUnlock()
 
 	// compile error
-	modules := make(map[string]*ast.Module, len(g.pathToPlicies))
-	errs := make(map[string]ast.Errors, len(g.pathToPlicies))
-	for path, p := range g.pathToPlicies {
-		if p.Module != nil {
-			modules[path] = p.Module
-		}
+	policies := g.policies()
+	errs := make(map[string]ast.Errors, len(policies))
+	for path := range policies {
 		errs[path] = make(ast.Errors, 0)
 	}
 
-	compiler := ast.NewCompiler()
-	compiler.Compile(modules)
-	if !compiler.Failed() {
-		return errs
-	}
-
-	for _, e := range compiler.Errors {
+	for _, e := range g.compileErrors() {
 		errs[e.Location.File] = append(errs[e.Location.File], e)
 	}
 	return errs
 }
 
+// GetStrictErrors returns the errors which are reported only in the strict mode.
+// e.g. unused imports, unused variables and deprecated built-in functions.
+// The caller should check that the policies are compiled without errors by GetErrors,
+// because the strict errors contain them too.
+func (g *GlobalCache) GetStrictErrors() map[string]ast.Errors {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	result := make(map[string]ast.Errors)
+	for _, e := range g.compile(true) {
+		result[e.Location.File] = append(result[e.Location.File], e)
+	}
+	return result
+}
+
+// compileErrors returns the errors of the compile which is not strict.
+// The errors are reused until the policies or the compile settings are changed. The caller should hold the read lock.
+func (g *GlobalCache) compileErrors() ast.Errors {
+	g.compileMu.Lock()
+	defer g.compileMu.Unlock()
+
+	if !g.compiled {
+		g.compileErrs, g.compiled = g.compile(false), true
+	}
+	return g.compileErrs
+}
+
+// invalidateCompileErrors forgets the errors of the last compile. The caller should hold the write lock.
+func (g *GlobalCache) invalidateCompileErrors() {
+	g.compileErrs, g.compiled = nil, false
+}
+
+// compile compiles all policies and returns the errors. The caller should hold the lock.
+func (g *GlobalCache) compile(strict bool) ast.Errors {
+	policies := g.policies()
+	modules := make(map[string]*ast.Module, len(policies))
+	for path, p := range policies {
+		if p.Module != nil {
+			modules[path] = g.withInputSchema(path, p.Module)
+		}
+	}
+
+	// The schemas in the METADATA annotations are used to type check the input.
+	compiler := ast.NewCompiler().WithStrict(strict).WithUseTypeCheckAnnotations(true)
+	if g.schemaSet != nil {
+		compiler = compiler.WithSchemas(g.schemaSet)
+	}
+	if g.capabilities != nil {
+		compiler = compiler.WithCapabilities(g.capabilities)
+	}
+	compiler.Compile(modules)
+	return compiler.Errors
+}
+
+// SetCapabilities sets the capabilities which is used to compile the policies.
+// nil means the capabilities of the current OPA version.
+func (g *GlobalCache) SetCapabilities(capabilities *ast.Capabilities) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invalidateCompileErrors()
+	g.capabilities = capabilities
+}
+
 func (g *GlobalCache) GetPackages() []ast.Ref {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
 	packages := make(map[string]ast.Ref)
-	for _, p := range g.pathToPlicies {
+	for _, p := range g.policies() {
 		if p.Module == nil {
 			continue
 		}
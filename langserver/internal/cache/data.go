@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/open-policy-agent/opa/ast"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// DataFileNames is the names of the data documents. The document is put under the path of its directory
+// like the bundle. e.g. roles/data.json -> data.roles
+var DataFileNames = []string{"data.json", "data.yaml", "data.yml"}
+
+// DataDocument is the JSON or YAML data document.
+type DataDocument struct {
+	RawText string
+	// Path is the ref of the document. e.g. data.roles
+	Path ast.Ref
+	// Value is the parsed document whose terms have the locations in the file.
+	// It is nil when the document cannot be parsed.
+	Value *ast.Term
+}
+
+// IsDataFile returns true when the file is the data document.
+func IsDataFile(path string) bool {
+	name := filepath.Base(path)
+	for _, n := range DataFileNames {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDataRoots sets the root directories of the data documents under the root directory.
+// The path of the document is relative to the deepest root directory which contains it.
+// The data documents which are not contained in any root directory are not loaded.
+func (g *GlobalCache) SetDataRoots(root string, dirs []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	root = filepath.Clean(root)
+	if len(dirs) == 0 {
+		delete(g.dataRoots, root)
+		return
+	}
+
+	result := make([]string, len(dirs))
+	for i, d := range dirs {
+		result[i] = filepath.Clean(d)
+	}
+	g.dataRoots[root] = result
+}
+
+// dataPath returns the ref of the data document. The caller should hold the lock.
+func (g *GlobalCache) dataPath(path string) (ast.Ref, bool) {
+	var dataRoot string
+	for _, dirs := range g.dataRoots {
+		for _, d := range dirs {
+			if isUnder(path, d) && len(d) > len(dataRoot) {
+				dataRoot = d
+			}
+		}
+	}
+	if dataRoot == "" {
+		return nil, false
+	}
+
+	rel, err := filepath.Rel(dataRoot, filepath.Dir(path))
+	if err != nil {
+		return nil, false
+	}
+
+	result := ast.Ref{ast.DefaultRootDocument}
+	if rel == "." {
+		return result, true
+	}
+	for _, s := range strings.Split(filepath.ToSlash(rel), "/") {
+		result = append(result, ast.StringTerm(s))
+	}
+	return result, true
+}
+
+// PutData puts the data document. The document which is not contained in the data roots is ignored.
+func (g *GlobalCache) PutData(path string, rawText string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	dataPath, ok := g.dataPath(path)
+	if !ok {
+		delete(g.pathToData, path)
+		return
+	}
+
+	doc := &DataDocument{RawText: rawText, Path: dataPath}
+	var err error
+	if filepath.Ext(path) == ".json" {
+		doc.Value, err = parseJSONData(path, rawText)
+	} else {
+		doc.Value, err = parseYAMLData(path, rawText)
+	}
+	if err != nil {
+		doc.Value = nil
+	}
+	g.pathToData[path] = doc
+}
+
+// GetDataDocuments returns the data documents which are sorted by the path.
+func (g *GlobalCache) GetDataDocuments() []*DataDocument {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	paths := make([]string, 0, len(g.pathToData))
+	for p := range g.pathToData {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	result := make([]*DataDocument, len(paths))
+	for i, p := range paths {
+		result[i] = g.pathToData[p]
+	}
+	return result
+}
+
+// parseJSONData parses the JSON document as the rego term to keep the locations of the keys.
+func parseJSONData(path string, rawText string) (*ast.Term, error) {
+	if !json.Valid([]byte(rawText)) {
+		return nil, fmt.Errorf("%s: invalid JSON", path)
+	}
+
+	stmts, _, err := ast.ParseStatementsWithOpts(path, rawText, ast.ParserOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("%s: unexpected statements", path)
+	}
+	body, ok := stmts[0].(ast.Body)
+	if !ok || len(body) != 1 {
+		return nil, fmt.Errorf("%s: unexpected statement", path)
+	}
+	term, ok := body[0].Terms.(*ast.Term)
+	if !ok {
+		return nil, fmt.Errorf("%s: unexpected expression", path)
+	}
+	return term, nil
+}
+
+// parseYAMLData parses the YAML document and converts it to the rego term with the locations.
+func parseYAMLData(path string, rawText string) (*ast.Term, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(rawText), &node); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(node.Content) == 0 {
+		return ast.ObjectTerm(), nil
+	}
+
+	lineOffsets := []int{0}
+	for i, c := range rawText {
+		if c == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+	c := yamlConverter{path: path, rawText: rawText, lineOffsets: lineOffsets}
+	return c.convert(node.Content[0])
+}
+
+type yamlConverter struct {
+	path        string
+	rawText     string
+	lineOffsets []int
+}
+
+func (c yamlConverter) convert(node *yaml.Node) (*ast.Term, error) {
+	var term *ast.Term
+	switch node.Kind {
+	case yaml.AliasNode:
+		return c.convert(node.Alias)
+	case yaml.MappingNode:
+		obj := ast.NewObject()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := ast.StringTerm(node.Content[i].Value)
+			key.Location = c.location(node.Content[i])
+			value, err := c.convert(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			obj.Insert(key, value)
+		}
+		term = ast.NewTerm(obj)
+	case yaml.SequenceNode:
+		elems := make([]*ast.Term, 0, len(node.Content))
+		for _, n := range node.Content {
+			elem, err := c.convert(n)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		term = ast.ArrayTerm(elems...)
+	case yaml.ScalarNode:
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		value, err := ast.InterfaceToValue(v)
+		if err != nil {
+			return nil, err
+		}
+		term = ast.NewTerm(value)
+	default:
+		return nil, fmt.Errorf("%s: unexpected yaml node at %d:%d", c.path, node.Line, node.Column)
+	}
+	term.Location = c.location(node)
+	return term, nil
+}
+
+// location returns the location of the node. The text is the scalar in the raw text.
+func (c yamlConverter) location(node *yaml.Node) *ast.Location {
+	if node.Line < 1 || node.Line > len(c.lineOffsets) {
+		return nil
+	}
+	// The column is counted in runes like the rego parser, but the offset is in bytes.
+	offset := c.lineOffsets[node.Line-1]
+	for i := 1; i < node.Column && offset < len(c.rawText); i++ {
+		_, size := utf8.DecodeRuneInString(c.rawText[offset:])
+		offset += size
+	}
+	if offset >= len(c.rawText) {
+		return nil
+	}
+
+	text := c.rawText[offset : offset+1]
+	if node.Kind == yaml.ScalarNode {
+		text = node.Value
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			if end := strings.IndexByte(c.rawText[offset+1:], c.rawText[offset]); end >= 0 {
+				text = c.rawText[offset : offset+end+2]
+			}
+		}
+	}
+	return &ast.Location{
+		Text:   []byte(text),
+		File:   c.path,
+		Row:    node.Line,
+		Col:    node.Column,
+		Offset: offset,
+	}
+}
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IgnoreFileNames is the files which have the ignore patterns in the gitignore format.
+// The patterns are relative to the directory of the file.
+var IgnoreFileNames = []string{".gitignore", ".regolsignore"}
+
+// alwaysIgnoredNames is the directories which are never loaded.
+var alwaysIgnoredNames = []string{".git"}
+
+// ignorePattern is the gitignore style pattern.
+type ignorePattern struct {
+	// segments is the slash separated pattern. "**" matches any directories.
+	segments []string
+	// negate re-includes the path which is ignored by the previous patterns.
+	negate bool
+	// dirOnly matches only the directory.
+	dirOnly bool
+}
+
+// parseIgnorePattern parses the line of the gitignore format. It returns false for the comment or the blank line.
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	// The pattern which doesn't contain "/" matches the name at any level.
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignorePattern{}, false
+	}
+	p.segments = strings.Split(line, "/")
+	return p, true
+}
+
+func parseIgnorePatterns(lines []string) []ignorePattern {
+	result := make([]ignorePattern, 0, len(lines))
+	for _, l := range lines {
+		if p, ok := parseIgnorePattern(l); ok {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// match returns true when the pattern matches the relative path or its parent directory.
+func (p ignorePattern) match(rel string, isDir bool) bool {
+	segments := strings.Split(rel, "/")
+	for i := 1; i <= len(segments); i++ {
+		if i == len(segments) && p.dirOnly && !isDir {
+			break
+		}
+		if matchSegments(p.segments, segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments returns true when the pattern segments match all path segments.
+func matchSegments(pattern, p []string) bool {
+	if len(pattern) == 0 {
+		return len(p) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(p); i++ {
+			if matchSegments(pattern[1:], p[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(p) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], p[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], p[1:])
+}
+
+// SetIgnorePatterns sets the glob patterns of the paths which are not loaded under the root directory.
+// The pattern is relative to the root and has the gitignore format.
+// These patterns take precedence over the ignore files.
+//
+//	vendor
+//	**/testdata/**
+//	policies/*_fixture.rego
+func (g *GlobalCache) SetIgnorePatterns(root string, patterns []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	root = filepath.Clean(root)
+	if len(patterns) == 0 {
+		delete(g.ignorePatterns, root)
+		return
+	}
+	g.ignorePatterns[root] = parseIgnorePatterns(patterns)
+}
+
+// loadIgnoreFiles reads the ignore files in the directory.
+func (g *GlobalCache) loadIgnoreFiles(dir string) {
+	lines := make([]string, 0)
+	for _, name := range IgnoreFileNames {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	if patterns := parseIgnorePatterns(lines); len(patterns) > 0 {
+		g.ignoreFiles[dir] = patterns
+	} else {
+		delete(g.ignoreFiles, dir)
+	}
+}
+
+// ClearIgnoreFiles forgets the patterns of the ignore files under the directory.
+// They are read again when the directory is loaded.
+func (g *GlobalCache) ClearIgnoreFiles(dir string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for d := range g.ignoreFiles {
+		if isUnder(d, dir) {
+			delete(g.ignoreFiles, d)
+		}
+	}
+}
+
+// IsIgnored returns true when the path matches the ignore patterns.
+func (g *GlobalCache) IsIgnored(p string, isDir bool) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.isIgnored(p, isDir)
+}
+
+// isIgnored returns true when the path matches the ignore patterns. The caller should hold the lock.
+// The patterns of the deeper ignore file take precedence, and the last matched pattern decides it like gitignore.
+func (g *GlobalCache) isIgnored(p string, isDir bool) bool {
+	p = filepath.Clean(p)
+	for _, name := range strings.Split(filepath.ToSlash(p), "/") {
+		for _, ignored := range alwaysIgnoredNames {
+			if name == ignored {
+				return true
+			}
+		}
+	}
+
+	ignored := false
+	apply := func(dir string, patterns []ignorePattern) {
+		if p == dir || !isUnder(p, dir) {
+			return
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return
+		}
+		for _, pattern := range patterns {
+			if pattern.match(filepath.ToSlash(rel), isDir) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+
+	// The patterns are applied from the shallower directory, so the deeper one wins.
+	dirs := sortRoots(g.ignoreFiles)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		apply(dirs[i], g.ignoreFiles[dirs[i]])
+	}
+	roots := sortRoots(g.ignorePatterns)
+	for i := len(roots) - 1; i >= 0; i-- {
+		apply(roots[i], g.ignorePatterns[roots[i]])
+	}
+	return ignored
+}
+
+// sortRoots returns the directories of the map. The deeper directory comes first,
+// and the directories of the same length are sorted lexically, so the order doesn't depend on the map.
+func sortRoots[T any](m map[string]T) []string {
+	result := make([]string, 0, len(m))
+	for d := range m {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i]) != len(result[j]) {
+			return len(result[i]) > len(result[j])
+		}
+		return result[i] < result[j]
+	})
+	return result
+}
+
+// isUnder returns true when the path is the directory or is contained in it.
+func isUnder(p, dir string) bool {
+	dir = strings.TrimSuffix(dir, string(filepath.Separator))
+	return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) || dir == "."
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// RegoVersion is the syntax version which is used to parse the policies.
+type RegoVersion int
+
+const (
+	// RegoVersionAuto parses the policy as v1 compatible when it imports rego.v1, otherwise as v0.
+	RegoVersionAuto RegoVersion = iota
+	// RegoVersionV0 parses the policy as v0.
+	RegoVersionV0
+	// RegoVersionV0FutureKeywords parses the policy as v0 with all future keywords imported.
+	RegoVersionV0FutureKeywords
+	// RegoVersionV1 parses the policy as v1.
+	RegoVersionV1
+)
+
+// ParseRegoVersion parses the rego version in the configuration.
+func ParseRegoVersion(s string) (RegoVersion, error) {
+	switch s {
+	case "", "auto":
+		return RegoVersionAuto, nil
+	case "v0":
+		return RegoVersionV0, nil
+	case "v0-future-keywords":
+		return RegoVersionV0FutureKeywords, nil
+	case "v1":
+		return RegoVersionV1, nil
+	default:
+		return RegoVersionAuto, fmt.Errorf("unknown rego version %q", s)
+	}
+}
+
+func (v RegoVersion) String() string {
+	switch v {
+	case RegoVersionV0:
+		return "v0"
+	case RegoVersionV0FutureKeywords:
+		return "v0-future-keywords"
+	case RegoVersionV1:
+		return "v1"
+	default:
+		return "auto"
+	}
+}
+
+var importRegoV1 = regexp.MustCompile(`(?m)^\s*import\s+rego\.v1\b`)
+
+// parserOptions returns the options to parse the policy. The METADATA annotations are always processed.
+func (v RegoVersion) parserOptions(rawText string) ast.ParserOptions {
+	opts := ast.ParserOptions{RegoVersion: ast.RegoV0, ProcessAnnotation: true}
+	switch v {
+	case RegoVersionV0:
+	case RegoVersionV0FutureKeywords:
+		opts.AllFutureKeywords = true
+	case RegoVersionV1:
+		opts.RegoVersion = ast.RegoV1
+	default:
+		if importRegoV1.MatchString(rawText) {
+			opts.RegoVersion = ast.RegoV0CompatV1
+		}
+	}
+	return opts
+}
+
+// SetRegoVersion sets the rego version of the policies under the directory and parses them again.
+// The empty directory sets the default version.
+func (g *GlobalCache) SetRegoVersion(dir string, version RegoVersion) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	if version == RegoVersionAuto {
+		delete(g.regoVersions, dir)
+	} else {
+		g.regoVersions[dir] = version
+	}
+
+	for _, pathToPolicies := range []map[string]*Policy{g.pathToPlicies, g.pathToOverlay} {
+		for path, p := range pathToPolicies {
+			if dir != "." && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+				continue
+			}
+			if err := g.put(pathToPolicies, path, p.RawText, p.Version); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RegoVersion returns the rego version of the policy.
+func (g *GlobalCache) RegoVersion(path string) RegoVersion {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.regoVersion(path)
+}
+
+// regoVersion returns the version of the nearest directory. The caller should hold the lock.
+func (g *GlobalCache) regoVersion(path string) RegoVersion {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if v, ok := g.regoVersions[dir]; ok {
+			return v
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return g.regoVersions["."]
+}
+
+// ParserOptions returns the options to parse the policy.
+func (g *GlobalCache) ParserOptions(path string, rawText string) ast.ParserOptions {
+	return g.RegoVersion(path).parserOptions(rawText)
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// inputSchema is the input schema of the policies which match the pattern.
+type inputSchema struct {
+	pattern ignorePattern
+	schema  ast.Ref
+}
+
+// SetSchemaSet sets the schemas which are used to type check the policies.
+// The schemas are referred from the METADATA annotations. e.g. schema.input
+func (g *GlobalCache) SetSchemaSet(ss *ast.SchemaSet) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invalidateCompileErrors()
+	g.schemaSet = ss
+}
+
+// GetSchema returns the schema of the ref. It returns nil when the schema doesn't exist.
+func (g *GlobalCache) GetSchema(ref ast.Ref) any {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.schemaSet == nil {
+		return nil
+	}
+	return g.schemaSet.Get(ref)
+}
+
+// SetInputSchemas sets the input schemas of the policies under the root directory.
+// The key is the gitignore style pattern of the policies which is relative to the root,
+// and the value is the ref of the schema. When the patterns overlap, the longest pattern is used.
+//
+//	policies/admission/**: schema.admission_review
+func (g *GlobalCache) SetInputSchemas(root string, schemas map[string]ast.Ref) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invalidateCompileErrors()
+
+	root = filepath.Clean(root)
+	if len(schemas) == 0 {
+		delete(g.inputSchemas, root)
+		return
+	}
+
+	patterns := make([]string, 0, len(schemas))
+	for p := range schemas {
+		patterns = append(patterns, p)
+	}
+	// The longer pattern is more specific.
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	result := make([]inputSchema, 0, len(patterns))
+	for _, p := range patterns {
+		pattern, ok := parseIgnorePattern(p)
+		if !ok || pattern.negate {
+			continue
+		}
+		result = append(result, inputSchema{pattern: pattern, schema: schemas[p]})
+	}
+	g.inputSchemas[root] = result
+}
+
+// InputSchemaRef returns the ref of the input schema which is configured for the policy.
+func (g *GlobalCache) InputSchemaRef(path string) (ast.Ref, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.inputSchemaRef(path)
+}
+
+// inputSchemaRef returns the ref of the input schema of the policy. The deepest root directory which matches wins.
+// The caller should hold the lock.
+func (g *GlobalCache) inputSchemaRef(path string) (ast.Ref, bool) {
+	for _, root := range sortRoots(g.inputSchemas) {
+		if path == root || !isUnder(path, root) {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		for _, s := range g.inputSchemas[root] {
+			if s.pattern.match(filepath.ToSlash(rel), false) {
+				return s.schema, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// withInputSchema returns the module which has the rule annotations of the configured input schema.
+// The METADATA schemas take precedence, so the module which declares them is returned as it is.
+// The caller should hold the lock.
+func (g *GlobalCache) withInputSchema(path string, module *ast.Module) *ast.Module {
+	schema, ok := g.inputSchemaRef(path)
+	if !ok {
+		return module
+	}
+	for _, a := range module.Annotations {
+		if len(a.Schemas) != 0 {
+			return module
+		}
+	}
+
+	// The compiler copies the module, so the shallow copy is enough.
+	cpy := *module
+	cpy.Annotations = append([]*ast.Annotations(nil), module.Annotations...)
+	for _, r := range module.Rules {
+		a := &ast.Annotations{
+			Scope:    "rule",
+			Schemas:  []*ast.SchemaAnnotation{{Path: ast.InputRootRef, Schema: schema}},
+			Location: r.Location,
+		}
+		cpy.Annotations = append(cpy.Annotations, a.Copy(r))
+	}
+	return &cpy
+}
@@ -7,8 +7,6 @@ package lsp
 
 import (
 	"bytes"
-	"encoding/base64"
-	"encoding/binary"
 	"encoding/json"
 	"strings"
 )
@@ -22,6 +20,7 @@ type InitializeParams struct {
 	RootPath string `json:"rootPath,omitempty"`
 
 	RootURI               DocumentURI        `json:"rootUri,omitempty"`
+	WorkspaceFolders      []WorkspaceFolder  `json:"workspaceFolders,omitempty"`
 	ClientInfo            ClientInfo         `json:"clientInfo,omitempty"`
 	Trace                 Trace              `json:"trace,omitempty"`
 	InitializationOptions any                `json:"initializationOptions,omitempty"`
@@ -43,6 +42,20 @@ func (p *InitializeParams) Root() DocumentURI {
 
 type DocumentURI string
 
+type WorkspaceFolder struct {
+	URI  DocumentURI `json:"uri"`
+	Name string      `json:"name"`
+}
+
+type WorkspaceFoldersChangeEvent struct {
+	Added   []WorkspaceFolder `json:"added"`
+	Removed []WorkspaceFolder `json:"removed"`
+}
+
+type DidChangeWorkspaceFoldersParams struct {
+	Event WorkspaceFoldersChangeEvent `json:"event"`
+}
+
 type ClientInfo struct {
 	Name    string `json:"name,omitempty"`
 	Version string `json:"version,omitempty"`
@@ -146,9 +159,15 @@ type TextDocumentClientCapabilities struct {
 		PrepareSupport bool `json:"prepareSupport,omitempty"`
 	} `json:"rename,omitempty"`
 
-	SemanticHighlightingCapabilities *struct {
-		SemanticHighlighting bool `json:"semanticHighlighting,omitempty"`
-	} `json:"semanticHighlightingCapabilities,omitempty"`
+	SemanticTokens *struct {
+		DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+
+		TokenTypes []string `json:"tokenTypes,omitempty"`
+
+		TokenModifiers []string `json:"tokenModifiers,omitempty"`
+
+		Formats []string `json:"formats,omitempty"`
+	} `json:"semanticTokens,omitempty"`
 
 	CodeAction struct {
 		DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
@@ -317,9 +336,11 @@ type ServerCapabilities struct {
 	DocumentFormattingProvider       bool                             `json:"documentFormattingProvider,omitempty"`
 	DocumentRangeFormattingProvider  bool                             `json:"documentRangeFormattingProvider,omitempty"`
 	DocumentOnTypeFormattingProvider *DocumentOnTypeFormattingOptions `json:"documentOnTypeFormattingProvider,omitempty"`
-	RenameProvider                   bool                             `json:"renameProvider,omitempty"`
+	RenameProvider                   any                              `json:"renameProvider,omitempty"` // bool | RenameOptions
 	ExecuteCommandProvider           *ExecuteCommandOptions           `json:"executeCommandProvider,omitempty"`
-	SemanticHighlighting             *SemanticHighlightingOptions     `json:"semanticHighlighting,omitempty"`
+	SemanticTokensProvider           *SemanticTokensOptions           `json:"semanticTokensProvider,omitempty"`
+	FoldingRangeProvider             bool                             `json:"foldingRangeProvider,omitempty"`
+	Workspace                        *WorkspaceServerCapabilities     `json:"workspace,omitempty"`
 
 	// XWorkspaceReferencesProvider indicates the server provides support for
 	// xworkspace/references. This is a Sourcegraph extension.
@@ -337,6 +358,21 @@ type ServerCapabilities struct {
 	Experimental any `json:"experimental,omitempty"`
 }
 
+type WorkspaceServerCapabilities struct {
+	WorkspaceFolders *WorkspaceFoldersServerCapabilities `json:"workspaceFolders,omitempty"`
+	FileOperations   *FileOperationsServerCapabilities   `json:"fileOperations,omitempty"`
+}
+
+type WorkspaceFoldersServerCapabilities struct {
+	Supported           bool `json:"supported,omitempty"`
+	ChangeNotifications bool `json:"changeNotifications,omitempty"`
+}
+
+type FileOperationsServerCapabilities struct {
+	DidCreate *FileOperationRegistrationOptions `json:"didCreate,omitempty"`
+	DidDelete *FileOperationRegistrationOptions `json:"didDelete,omitempty"`
+}
+
 type CompletionOptions struct {
 	ResolveProvider   bool     `json:"resolveProvider,omitempty"`
 	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
@@ -355,6 +391,10 @@ type SignatureHelpOptions struct {
 	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
 }
 
+type RenameOptions struct {
+	PrepareProvider bool `json:"prepareProvider,omitempty"`
+}
+
 type ExecuteCommandOptions struct {
 	Commands []string `json:"commands"`
 }
@@ -364,8 +404,15 @@ type ExecuteCommandParams struct {
 	Arguments []any  `json:"arguments,omitempty"`
 }
 
-type SemanticHighlightingOptions struct {
-	Scopes [][]string `json:"scopes,omitempty"`
+type SemanticTokensOptions struct {
+	Legend SemanticTokensLegend `json:"legend"`
+	Range  bool                 `json:"range,omitempty"`
+	Full   bool                 `json:"full,omitempty"`
+}
+
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
 }
 
 type CompletionItemKind int
@@ -435,7 +482,7 @@ type CompletionItem struct {
 	Label               string             `json:"label"`
 	Kind                CompletionItemKind `json:"kind,omitempty"`
 	Detail              string             `json:"detail,omitempty"`
-	Documentation       string             `json:"documentation,omitempty"`
+	Documentation       *MarkupContent     `json:"documentation,omitempty"`
 	SortText            string             `json:"sortText,omitempty"`
 	FilterText          string             `json:"filterText,omitempty"`
 	InsertText          string             `json:"insertText,omitempty"`
@@ -457,6 +504,18 @@ const (
 	CTKTriggerCharacter                       = 2
 )
 
+type MarkupKind string
+
+const (
+	MKPlainText MarkupKind = "plaintext"
+	MKMarkdown  MarkupKind = "markdown"
+)
+
+type MarkupContent struct {
+	Kind  MarkupKind `json:"kind"`
+	Value string     `json:"value"`
+}
+
 type DocumentationFormat string
 
 const (
@@ -556,7 +615,7 @@ type SignatureHelp struct {
 
 type SignatureInformation struct {
 	Label         string                 `json:"label"`
-	Documentation string                 `json:"documentation,omitempty"`
+	Documentation *MarkupContent         `json:"documentation,omitempty"`
 	Parameters    []ParameterInformation `json:"parameters,omitempty"`
 }
 
@@ -590,6 +649,24 @@ type DocumentHighlight struct {
 	Kind  int   `json:"kind,omitempty"`
 }
 
+type FoldingRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type FoldingRangeKind string
+
+const (
+	FRKComment FoldingRangeKind = "comment"
+	FRKImports FoldingRangeKind = "imports"
+	FRKRegion  FoldingRangeKind = "region"
+)
+
+type FoldingRange struct {
+	StartLine int              `json:"startLine"`
+	EndLine   int              `json:"endLine"`
+	Kind      FoldingRangeKind `json:"kind,omitempty"`
+}
+
 type DocumentSymbolParams struct {
 	TextDocument TextDocumentIdentifier `json:"textDocument"`
 }
@@ -666,6 +743,16 @@ type SymbolInformation struct {
 	ContainerName string     `json:"containerName,omitempty"`
 }
 
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           SymbolKind       `json:"kind"`
+	Deprecated     bool             `json:"deprecated,omitempty"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
 type WorkspaceSymbolParams struct {
 	Query string `json:"query"`
 	Limit int    `json:"limit"`
@@ -692,6 +779,14 @@ type CodeActionParams struct {
 	Context      CodeActionContext      `json:"context"`
 }
 
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        CodeActionKind `json:"kind,omitempty"`
+	Diagnostics []Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool           `json:"isPreferred,omitempty"`
+	Edit        *WorkspaceEdit `json:"edit,omitempty"`
+}
+
 type CodeLensParams struct {
 	TextDocument TextDocumentIdentifier `json:"textDocument"`
 }
@@ -792,134 +887,104 @@ type DidChangeWatchedFilesParams struct {
 	Changes []FileEvent `json:"changes"`
 }
 
-type PublishDiagnosticsParams struct {
-	URI         DocumentURI  `json:"uri"`
-	Diagnostics []Diagnostic `json:"diagnostics"`
+type Registration struct {
+	ID              string `json:"id"`
+	Method          string `json:"method"`
+	RegisterOptions any    `json:"registerOptions,omitempty"`
 }
 
-type DocumentRangeFormattingParams struct {
-	TextDocument TextDocumentIdentifier `json:"textDocument"`
-	Range        Range                  `json:"range"`
-	Options      FormattingOptions      `json:"options"`
+type RegistrationParams struct {
+	Registrations []Registration `json:"registrations"`
 }
 
-type DocumentOnTypeFormattingParams struct {
-	TextDocument TextDocumentIdentifier `json:"textDocument"`
-	Position     Position               `json:"position"`
-	Ch           string                 `json:"ch"`
-	Options      FormattingOptions      `json:"formattingOptions"`
+type DidChangeWatchedFilesRegistrationOptions struct {
+	Watchers []FileSystemWatcher `json:"watchers"`
 }
 
-type CancelParams struct {
-	ID ID `json:"id"`
-}
+type WatchKind int
 
-type SemanticHighlightingParams struct {
-	TextDocument VersionedTextDocumentIdentifier   `json:"textDocument"`
-	Lines        []SemanticHighlightingInformation `json:"lines"`
-}
-
-// SemanticHighlightingInformation represents a semantic highlighting
-// information that has to be applied on a specific line of the text
-// document.
-type SemanticHighlightingInformation struct {
-	// Line is the zero-based line position in the text document.
-	Line int `json:"line"`
+const (
+	WatchCreate WatchKind = 1
+	WatchChange WatchKind = 2
+	WatchDelete WatchKind = 4
+)
 
-	// Tokens is a base64 encoded string representing every single highlighted
-	// characters with its start position, length and the "lookup table" index of
-	// the semantic highlighting [TextMate scopes](https://manual.macromates.com/en/language_grammars).
-	// If the `tokens` is empty or not defined, then no highlighted positions are
-	// available for the line.
-	Tokens SemanticHighlightingTokens `json:"tokens,omitempty"`
+type FileSystemWatcher struct {
+	GlobPattern string    `json:"globPattern"`
+	Kind        WatchKind `json:"kind,omitempty"`
 }
 
-type semanticHighlightingInformation struct {
-	Line   int     `json:"line"`
-	Tokens *string `json:"tokens"`
+type FileOperationRegistrationOptions struct {
+	Filters []FileOperationFilter `json:"filters"`
 }
 
-// MarshalJSON implements json.Marshaler.
-func (v *SemanticHighlightingInformation) MarshalJSON() ([]byte, error) {
-	tokens := string(v.Tokens.Serialize())
-	return json.Marshal(&semanticHighlightingInformation{
-		Line:   v.Line,
-		Tokens: &tokens,
-	})
+type FileOperationFilter struct {
+	Scheme  string               `json:"scheme,omitempty"`
+	Pattern FileOperationPattern `json:"pattern"`
 }
 
-// UnmarshalJSON implements json.Unmarshaler.
-func (v *SemanticHighlightingInformation) UnmarshalJSON(data []byte) error {
-	var info semanticHighlightingInformation
-	err := json.Unmarshal(data, &info)
-	if err != nil {
-		return err
-	}
+type FileOperationPatternKind string
 
-	if info.Tokens != nil {
-		v.Tokens, err = DeserializeSemanticHighlightingTokens([]byte(*info.Tokens))
-		if err != nil {
-			return err
-		}
-	}
+const (
+	FOPKFile   FileOperationPatternKind = "file"
+	FOPKFolder FileOperationPatternKind = "folder"
+)
 
-	v.Line = info.Line
-	return nil
+type FileOperationPattern struct {
+	Glob    string                   `json:"glob"`
+	Matches FileOperationPatternKind `json:"matches,omitempty"`
+}
+
+type CreateFilesParams struct {
+	Files []FileCreate `json:"files"`
 }
 
-type SemanticHighlightingTokens []SemanticHighlightingToken
+type FileCreate struct {
+	URI DocumentURI `json:"uri"`
+}
 
-func (v SemanticHighlightingTokens) Serialize() []byte {
-	var chunks [][]byte
+type DeleteFilesParams struct {
+	Files []FileDelete `json:"files"`
+}
 
-	// Writes each token to `tokens` in the byte format specified by the LSP
-	// proposal. Described below:
-	// |<---- 4 bytes ---->|<-- 2 bytes -->|<--- 2 bytes -->|
-	// |    character      |  length       |    index       |
-	for _, token := range v {
-		chunk := make([]byte, 8)
-		binary.BigEndian.PutUint32(chunk[:4], token.Character)
-		binary.BigEndian.PutUint16(chunk[4:6], token.Length)
-		binary.BigEndian.PutUint16(chunk[6:], token.Scope)
-		chunks = append(chunks, chunk)
-	}
+type FileDelete struct {
+	URI DocumentURI `json:"uri"`
+}
 
-	src := make([]byte, len(chunks)*8)
-	for i, chunk := range chunks {
-		copy(src[i*8:i*8+8], chunk)
-	}
+type PublishDiagnosticsParams struct {
+	URI         DocumentURI  `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
 
-	dst := make([]byte, base64.StdEncoding.EncodedLen(len(src)))
-	base64.StdEncoding.Encode(dst, src)
-	return dst
+type DocumentRangeFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Options      FormattingOptions      `json:"options"`
 }
 
-func DeserializeSemanticHighlightingTokens(src []byte) (SemanticHighlightingTokens, error) {
-	dst := make([]byte, base64.StdEncoding.DecodedLen(len(src)))
-	n, err := base64.StdEncoding.Decode(dst, src)
-	if err != nil {
-		return nil, err
-	}
+type DocumentOnTypeFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+	Ch           string                 `json:"ch"`
+	Options      FormattingOptions      `json:"formattingOptions"`
+}
 
-	var chunks [][]byte
-	for i := 7; i < len(dst[:n]); i += 8 {
-		chunks = append(chunks, dst[i-7:i+1])
-	}
+type CancelParams struct {
+	ID ID `json:"id"`
+}
 
-	var tokens SemanticHighlightingTokens
-	for _, chunk := range chunks {
-		tokens = append(tokens, SemanticHighlightingToken{
-			Character: binary.BigEndian.Uint32(chunk[:4]),
-			Length:    binary.BigEndian.Uint16(chunk[4:6]),
-			Scope:     binary.BigEndian.Uint16(chunk[6:]),
-		})
-	}
+type SemanticTokensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
 
-	return tokens, nil
+type SemanticTokensRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
 }
 
-type SemanticHighlightingToken struct {
-	Character uint32
-	Length    uint16
-	Scope     uint16
+// SemanticTokens represents the tokens with the relative positions.
+//
+// Each token is encoded as 5 integers: deltaLine, deltaStartChar, length, tokenType and tokenModifiers.
+type SemanticTokens struct {
+	Data []uint32 `json:"data"`
 }
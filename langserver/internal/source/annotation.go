@@ -0,0 +1,130 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// metadataPrefix is the first line of the comment block of the METADATA annotations.
+const metadataPrefix = "METADATA"
+
+// ruleDocumentation returns the markdown of the METADATA annotations of the rule.
+// When the rule doesn't have the annotations, it returns the comment block above the rule.
+func ruleDocumentation(rule *ast.Rule) string {
+	if rule.Module == nil {
+		return ""
+	}
+
+	as, _ := ast.BuildAnnotationSet([]*ast.Module{rule.Module})
+	annotations := as.GetRuleScope(rule)
+	if a := as.GetDocumentScope(rule.Ref().GroundPrefix()); a != nil {
+		annotations = append(annotations, a)
+	}
+
+	docs := make([]string, 0, len(annotations))
+	for _, a := range annotations {
+		if doc := renderAnnotations(a); doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) != 0 {
+		return strings.Join(docs, "\n\n---\n\n")
+	}
+	return commentsAbove(rule.Module, rule.Location.Row)
+}
+
+// packageDocumentation returns the markdown of the METADATA annotations or the comment block of the package.
+func packageDocumentation(module *ast.Module) string {
+	docs := make([]string, 0)
+	for _, a := range module.Annotations {
+		if a.Scope != "package" && a.Scope != "subpackages" {
+			continue
+		}
+		if doc := renderAnnotations(a); doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) != 0 {
+		return strings.Join(docs, "\n\n---\n\n")
+	}
+	return commentsAbove(module, module.Package.Location.Row)
+}
+
+// renderAnnotations renders the title, the description, the related resources, the custom and the scope.
+func renderAnnotations(a *ast.Annotations) string {
+	var b strings.Builder
+	if a.Title != "" {
+		fmt.Fprintf(&b, "### %s\n\n", a.Title)
+	}
+	if a.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(a.Description))
+	}
+
+	if len(a.RelatedResources) != 0 {
+		b.WriteString("**Related resources**\n\n")
+		for _, r := range a.RelatedResources {
+			ref := r.Ref.String()
+			if r.Description != "" {
+				fmt.Fprintf(&b, "- [%s](%s)\n", r.Description, ref)
+			} else {
+				fmt.Fprintf(&b, "- <%s>\n", ref)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(a.Custom) != 0 {
+		keys := make([]string, 0, len(a.Custom))
+		for k := range a.Custom {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("**Custom**\n\n")
+		for _, k := range keys {
+			v, err := json.Marshal(a.Custom[k])
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "- %s: `%s`\n", k, v)
+		}
+		b.WriteString("\n")
+	}
+
+	// The annotations which have only the schemas are not documents.
+	if b.Len() == 0 {
+		return ""
+	}
+	fmt.Fprintf(&b, "*Scope: %s*", a.Scope)
+	return b.String()
+}
+
+// commentsAbove returns the comment block which ends right above the row.
+// The comment block of the METADATA annotations is ignored.
+func commentsAbove(module *ast.Module, row int) string {
+	lines := make([]string, 0)
+	for i := len(module.Comments) - 1; i >= 0; i-- {
+		c := module.Comments[i]
+		if c.Location.Row >= row {
+			continue
+		}
+		// The trailing comment of the code is not the document.
+		if c.Location.Row != row-len(lines)-1 || c.Location.Col != 1 {
+			break
+		}
+		lines = append(lines, strings.TrimPrefix(string(c.Text), " "))
+	}
+	if len(lines) == 0 || strings.TrimSpace(lines[len(lines)-1]) == metadataPrefix {
+		return ""
+	}
+
+	// The comments are collected from the bottom.
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
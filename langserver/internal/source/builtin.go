@@ -0,0 +1,118 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// builtinReferenceURL is the policy reference page which documents the built-in functions.
+const builtinReferenceURL = "https://www.openpolicyagent.org/docs/latest/policy-reference/"
+
+// builtinSignature returns the signature of the built-in function with the argument names and the result.
+//
+//	sprintf(format: string, values: array[any]) => output: string
+func builtinSignature(b *ast.Builtin) string {
+	return fmt.Sprintf("%s%s => %s", b.Name, b.Decl.NamedFuncArgs(), b.Decl.NamedResult())
+}
+
+// builtinDocumentation returns the markdown of the description, the arguments, the result,
+// the categories and the link to the policy reference of the built-in function.
+func builtinDocumentation(b *ast.Builtin) string {
+	return joinDocuments(
+		builtinDescription(b),
+		builtinArguments(b),
+		builtinResult(b),
+		builtinReference(b),
+	)
+}
+
+// builtinSignatureDocumentation is builtinDocumentation without the arguments,
+// because the signature help documents each argument.
+func builtinSignatureDocumentation(b *ast.Builtin) string {
+	return joinDocuments(
+		builtinDescription(b),
+		builtinResult(b),
+		builtinReference(b),
+	)
+}
+
+func builtinDescription(b *ast.Builtin) string {
+	description := strings.TrimSpace(b.Description)
+	if !b.IsDeprecated() {
+		return description
+	}
+	return joinDocuments("**Deprecated**", description)
+}
+
+func builtinArguments(b *ast.Builtin) string {
+	args := b.Decl.NamedFuncArgs().Args
+	if len(args) == 0 {
+		return ""
+	}
+
+	var s strings.Builder
+	s.WriteString("**Arguments**\n")
+	for _, a := range args {
+		s.WriteString("\n" + namedTypeItem(a))
+	}
+	return s.String()
+}
+
+func builtinResult(b *ast.Builtin) string {
+	result := b.Decl.NamedResult()
+	if result == nil {
+		return ""
+	}
+	return "**Returns**\n\n" + namedTypeItem(result)
+}
+
+// builtinReference returns the categories and the link to the built-in function in the policy reference.
+func builtinReference(b *ast.Builtin) string {
+	link := fmt.Sprintf("[Policy reference](%s)", builtinReferenceLink(b))
+	if len(b.Categories) == 0 {
+		return link
+	}
+	return fmt.Sprintf("*Categories: %s*\n\n%s", strings.Join(b.Categories, ", "), link)
+}
+
+// builtinReferenceLink returns the anchor link of the built-in function in the policy reference.
+// The deprecated built-in functions are not documented, so the link of the section is returned.
+//
+//	sprintf    -> #builtin-strings-sprintf
+//	object.get -> #builtin-object-objectget
+func builtinReferenceLink(b *ast.Builtin) string {
+	var category string
+	if len(b.Categories) != 0 {
+		category = b.Categories[0]
+	} else if i := strings.Index(b.Name, "."); i > 0 {
+		category = b.Name[:i]
+	}
+	if category == "" || b.IsDeprecated() {
+		return builtinReferenceURL + "#built-in-functions"
+	}
+	return fmt.Sprintf("%s#builtin-%s-%s", builtinReferenceURL, category, strings.ReplaceAll(b.Name, ".", ""))
+}
+
+// namedTypeItem renders the argument or the result as the markdown list item.
+// e.g. "- `format: string`: string with formatting verbs"
+func namedTypeItem(t types.Type) string {
+	named, ok := t.(*types.NamedType)
+	if !ok || named.Descr == "" {
+		return fmt.Sprintf("- `%s`", t)
+	}
+	return fmt.Sprintf("- `%s`: %s", t, named.Descr)
+}
+
+// joinDocuments joins the non-empty documents with the blank line.
+func joinDocuments(docs ...string) string {
+	result := make([]string, 0, len(docs))
+	for _, d := range docs {
+		if d != "" {
+			result = append(result, d)
+		}
+	}
+	return strings.Join(result, "\n\n")
+}
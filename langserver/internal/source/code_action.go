@@ -0,0 +1,239 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+type CodeAction struct {
+	Title string
+	// Error is the error which the code action fixes.
+	Error *ast.Error
+	Edits []CodeActionEdit
+}
+
+// CodeActionEdit replaces the text of Location with NewText.
+// When Location.Text is empty, NewText is inserted at Location.
+type CodeActionEdit struct {
+	Location *ast.Location
+	NewText  string
+}
+
+// ListCodeActions lists quick fixes for the errors between startRow and endRow.
+func (p *Project) ListCodeActions(path string, startRow, endRow int) []CodeAction {
+	policy := p.cache.Get(path)
+	if policy == nil || policy.Module == nil {
+		return nil
+	}
+
+	result := make([]CodeAction, 0)
+	for _, e := range p.GetErrors(path)[path] {
+		if e.Location == nil || e.Location.Row < startRow || e.Location.Row > endRow {
+			continue
+		}
+
+		switch e.Code {
+		case ast.UnsafeVarErr:
+			result = append(result, p.listUnsafeVarCodeActions(e, policy.Module, policy.RawText)...)
+		case ast.TypeErr:
+			result = append(result, p.listUndefinedFunctionCodeActions(e, policy.Module)...)
+		case ast.CompileErr:
+			result = append(result, p.listAssignCodeActions(e, policy.Module)...)
+		}
+	}
+	return result
+}
+
+// listUnsafeVarCodeActions fixes "var x is unsafe" error.
+//
+// When x is the name of a package, import the package. Otherwise, declare x with `some`.
+func (p *Project) listUnsafeVarCodeActions(err *ast.Error, module *ast.Module, rawText string) []CodeAction {
+	name, ok := parseErrorVar(err.Message, "is unsafe")
+	if !ok {
+		return nil
+	}
+
+	if actions := p.listImportCodeActions(err, module, name); len(actions) > 0 {
+		return actions
+	}
+
+	// `some` can be declared only in the rule body.
+	expr := findExprAt(module, err.Location)
+	if expr == nil {
+		return nil
+	}
+	// x := lib.rule reports that x is unsafe because lib is unsafe.
+	if expr.IsAssignment() && expr.Operand(0).Value.Compare(ast.Var(name)) == 0 {
+		return nil
+	}
+
+	lines := strings.Split(rawText, "\n")
+	if err.Location.Row > len(lines) {
+		return nil
+	}
+	line := lines[err.Location.Row-1]
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+	return []CodeAction{
+		{
+			Title: fmt.Sprintf("Declare %s with some", name),
+			Error: err,
+			Edits: []CodeActionEdit{
+				{
+					Location: &ast.Location{Row: err.Location.Row, Col: 1, File: err.Location.File},
+					NewText:  fmt.Sprintf("%ssome %s\n", indent, name),
+				},
+			},
+		},
+	}
+}
+
+// listUndefinedFunctionCodeActions fixes "undefined function lib.f" error by importing the package.
+func (p *Project) listUndefinedFunctionCodeActions(err *ast.Error, module *ast.Module) []CodeAction {
+	name, ok := strings.CutPrefix(err.Message, "undefined function ")
+	if !ok {
+		return nil
+	}
+	name, _, ok = strings.Cut(name, ".")
+	if !ok {
+		return nil
+	}
+	return p.listImportCodeActions(err, module, name)
+}
+
+// listImportCodeActions lists the imports of the packages whose last segment is name.
+func (p *Project) listImportCodeActions(err *ast.Error, module *ast.Module, name string) []CodeAction {
+	result := make([]CodeAction, 0)
+	for _, pkg := range p.cache.GetPackages() {
+		if pkg[len(pkg)-1].Value.Compare(ast.String(name)) != 0 {
+			continue
+		}
+		if isImported(pkg, module.Imports) || pkg.Equal(module.Package.Path) {
+			continue
+		}
+		edit := createImportTextEdit(module, pkg)
+		result = append(result, CodeAction{
+			Title: fmt.Sprintf("Import %s", pkg.String()),
+			Error: err,
+			Edits: []CodeActionEdit{
+				{
+					Location: &ast.Location{Row: edit.Row, Col: edit.Col, File: err.Location.File},
+					NewText:  edit.Text,
+				},
+			},
+		})
+	}
+	return result
+}
+
+// listAssignCodeActions fixes "var x referenced above" and "var x assigned above" errors.
+//
+//	x = 1
+//	x := 2 # var x referenced above
+//
+// The first `=` is replaced with `:=`, and the second `:=` is replaced with `=`.
+func (p *Project) listAssignCodeActions(err *ast.Error, module *ast.Module) []CodeAction {
+	name, ok := parseErrorVar(err.Message, "referenced above")
+	if !ok {
+		name, ok = parseErrorVar(err.Message, "assigned above")
+	}
+	if !ok {
+		return nil
+	}
+
+	assign := findAssignExprForVar(module, err.Location)
+	if assign == nil {
+		return nil
+	}
+	assignOperator := assign.Terms.([]*ast.Term)[0].Location
+	replaceAssign := CodeActionEdit{Location: assignOperator, NewText: "="}
+
+	if strings.HasSuffix(err.Message, "referenced above") {
+		if eq := findEqualityExprForVar(module, ast.Var(name), err.Location); eq != nil {
+			return []CodeAction{
+				{
+					Title: fmt.Sprintf(`Replace "=" with ":=" to declare %s`, name),
+					Error: err,
+					Edits: []CodeActionEdit{
+						{Location: eq.Terms.([]*ast.Term)[0].Location, NewText: ":="},
+						replaceAssign,
+					},
+				},
+			}
+		}
+	}
+
+	return []CodeAction{
+		{
+			Title: `Replace ":=" with "="`,
+			Error: err,
+			Edits: []CodeActionEdit{replaceAssign},
+		},
+	}
+}
+
+// parseErrorVar parses the message like "var x is unsafe" and returns "x".
+func parseErrorVar(message, suffix string) (string, bool) {
+	if !strings.HasPrefix(message, "var ") || !strings.HasSuffix(message, " "+suffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(message, "var "), " "+suffix), true
+}
+
+// findExprAt returns the expression in the rule body which starts at the location.
+func findExprAt(module *ast.Module, loc *ast.Location) *ast.Expr {
+	var result *ast.Expr
+	for _, r := range module.Rules {
+		ast.WalkExprs(r, func(e *ast.Expr) bool {
+			if e.Location != nil && e.Location.Offset == loc.Offset && e.Location.Row == loc.Row {
+				result = e
+				return true
+			}
+			return false
+		})
+	}
+	return result
+}
+
+// findAssignExprForVar returns the `:=` expression whose left hand side is at the location.
+func findAssignExprForVar(module *ast.Module, loc *ast.Location) *ast.Expr {
+	var result *ast.Expr
+	for _, r := range module.Rules {
+		ast.WalkExprs(r, func(e *ast.Expr) bool {
+			if !e.IsAssignment() {
+				return false
+			}
+			terms := e.Terms.([]*ast.Term)
+			if terms[1].Location != nil && terms[1].Location.Offset == loc.Offset {
+				result = e
+				return true
+			}
+			return false
+		})
+	}
+	return result
+}
+
+// findEqualityExprForVar returns the first `v = ...` expression before the location.
+func findEqualityExprForVar(module *ast.Module, v ast.Var, loc *ast.Location) *ast.Expr {
+	var result *ast.Expr
+	for _, r := range module.Rules {
+		if !in(loc, r.Loc()) {
+			continue
+		}
+		ast.WalkExprs(r, func(e *ast.Expr) bool {
+			if result != nil || !e.IsEquality() || e.Location == nil || e.Location.Offset >= loc.Offset {
+				return result != nil
+			}
+			terms := e.Terms.([]*ast.Term)
+			if terms[1].Value.Compare(v) == 0 {
+				result = e
+				return true
+			}
+			return false
+		})
+	}
+	return result
+}
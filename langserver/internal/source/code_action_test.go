@@ -0,0 +1,203 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/kitagry/regols/langserver/internal/source/helper"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+func TestProject_ListCodeActions(t *testing.T) {
+	tests := map[string]struct {
+		files        map[string]source.File
+		expectResult []source.CodeAction
+	}{
+		"Should import the package of unsafe var": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	lib.is_hello(msg)|
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+is_hello(msg) {
+	msg == "hello"
+}`,
+				},
+			},
+			expectResult: []source.CodeAction{
+				{
+					Title: "Import data.lib",
+					Edits: []source.CodeActionEdit{
+						{
+							Location: &ast.Location{Row: 2, Col: 1, File: "src.rego"},
+							NewText:  "\nimport data.lib\n",
+						},
+					},
+				},
+			},
+		},
+		"Should import the package of unsafe var in assignment": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.util
+
+violation[msg] {
+	x := lib.rule|
+	msg := x
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+rule := "hello"`,
+				},
+				"util.rego": {
+					RawText: `package util`,
+				},
+			},
+			expectResult: []source.CodeAction{
+				{
+					Title: "Import data.lib",
+					Edits: []source.CodeActionEdit{
+						{
+							Location: &ast.Location{Row: 4, Col: 1, File: "src.rego"},
+							NewText:  "import data.lib\n",
+						},
+					},
+				},
+			},
+		},
+		"Should declare unsafe var with some": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	msg := x|
+}`,
+				},
+			},
+			expectResult: []source.CodeAction{
+				{
+					Title: "Declare x with some",
+					Edits: []source.CodeActionEdit{
+						{
+							Location: &ast.Location{Row: 4, Col: 1, File: "src.rego"},
+							NewText:  "\tsome x\n",
+						},
+					},
+				},
+			},
+		},
+		"Should declare the referenced var with :=": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	msg = "hello"
+	msg := "world"|
+}`,
+				},
+			},
+			expectResult: []source.CodeAction{
+				{
+					Title: `Replace "=" with ":=" to declare msg`,
+					Edits: []source.CodeActionEdit{
+						{
+							Location: &ast.Location{
+								Row:    4,
+								Col:    6,
+								Offset: len("package src\n\nviolation[msg] {\n\tmsg "),
+								Text:   []byte("="),
+								File:   "src.rego",
+							},
+							NewText: ":=",
+						},
+						{
+							Location: &ast.Location{
+								Row:    5,
+								Col:    6,
+								Offset: len("package src\n\nviolation[msg] {\n\tmsg = \"hello\"\n\tmsg "),
+								Text:   []byte(":="),
+								File:   "src.rego",
+							},
+							NewText: "=",
+						},
+					},
+				},
+			},
+		},
+		"Should replace the reassignment with =": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	x := 1
+	x := 2|
+	msg := x
+}`,
+				},
+			},
+			expectResult: []source.CodeAction{
+				{
+					Title: `Replace ":=" with "="`,
+					Edits: []source.CodeActionEdit{
+						{
+							Location: &ast.Location{
+								Row:    5,
+								Col:    4,
+								Offset: len("package src\n\nviolation[msg] {\n\tx := 1\n\tx "),
+								Text:   []byte(":="),
+								File:   "src.rego",
+							},
+							NewText: "=",
+						},
+					},
+				},
+			},
+		},
+		"Should not list code actions out of range": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+|
+violation[msg] {
+	msg := x
+}`,
+				},
+			},
+			expectResult: []source.CodeAction{},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files, location, err := helper.GetAstLocation(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := source.NewProjectWithFiles(files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := p.ListCodeActions(location.File, location.Row, location.Row)
+			if diff := cmp.Diff(tt.expectResult, got, cmpopts.IgnoreFields(source.CodeAction{}, "Error")); diff != "" {
+				t.Errorf("ListCodeActions result diff (-expect +got):\n%s", diff)
+			}
+		})
+	}
+}
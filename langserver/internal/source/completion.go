@@ -9,11 +9,24 @@ import (
 )
 
 type CompletionItem struct {
-	Label               string
-	Kind                CompletionKind
-	Detail              string
+	Label  string
+	Kind   CompletionKind
+	Detail string
+	// Documentation is the markdown document. e.g. METADATA annotations
+	Documentation       string
 	TextEdit            *TextEdit
 	AdditionalTextEdits []TextEdit
+	// Data is set when the detail and the documentation are resolved lazily by ResolveCompletionItem.
+	Data *CompletionData
+}
+
+// CompletionData identifies the rule or the built-in function of the completion item.
+type CompletionData struct {
+	// Package is the package of the rule. e.g. data.lib
+	// It is empty for the built-in function.
+	Package string `json:"package,omitempty"`
+	// Name is the name of the rule or the built-in function. e.g. is_hello, json.patch
+	Name string `json:"name"`
 }
 
 type TextEdit struct {
@@ -31,6 +44,8 @@ const (
 	FunctionItem
 	BuiltinFunctionItem
 	ImportItem
+	// FieldItem is the key of the data document or the input document.
+	FieldItem
 )
 
 func (p *Project) ListCompletionItems(location *ast.Location) ([]CompletionItem, error) {
@@ -79,7 +94,9 @@ func (p *Project) listCompletionCandidates(location *ast.Location, target *ast.T
 
 	for _, r := range policy.Module.Rules {
 		if in(location, r.Loc()) {
-			return p.listCompletionItemsForTerms(location, target)
+			result := p.listCompletionItemsForTerms(location, target)
+			// The sample input documents are used for the keys which the schemas don't have.
+			return append(result, p.listSampleInputCompletionItems(location, target)...)
 		}
 	}
 
@@ -157,6 +174,8 @@ func (p *Project) listCompletionItemsForTerms(location *ast.Location, target *as
 
 	result = append(result, p.listRules(location, target)...)
 	result = append(result, p.listBuiltinFunctions(location, target)...)
+	result = append(result, p.listDataCompletionItems(location, target)...)
+	result = append(result, p.listInputCompletionItems(location, target)...)
 
 	return result
 }
@@ -176,27 +195,7 @@ func (p *Project) listLibraryVariables(loc *ast.Location, module *ast.Module) []
 	for _, p := range pkgs {
 		if !isImported(p, module.Imports) && !p.Equal(module.Package.Path) {
 			label := string(p[len(p)-1].Value.(ast.String))
-
-			var importTextEdit TextEdit
-			if len(module.Imports) != 0 {
-				lastImportedRow := 0
-				for _, imp := range module.Imports {
-					if lastImportedRow < imp.Location.Row {
-						lastImportedRow = imp.Location.Row
-					}
-					importTextEdit = TextEdit{
-						Row:  lastImportedRow + 1,
-						Col:  1,
-						Text: fmt.Sprintf("import %s\n", p.String()),
-					}
-				}
-			} else {
-				importTextEdit = TextEdit{
-					Row:  module.Package.Location.Row + 1,
-					Col:  1,
-					Text: fmt.Sprintf("\nimport %s\n", p.String()),
-				}
-			}
+			importTextEdit := createImportTextEdit(module, p)
 			result = append(result, CompletionItem{
 				Label: label,
 				Kind:  PackageItem,
@@ -214,6 +213,29 @@ func (p *Project) listLibraryVariables(loc *ast.Location, module *ast.Module) []
 	return result
 }
 
+// createImportTextEdit returns the text edit which adds the import of pkg after the last import.
+func createImportTextEdit(module *ast.Module, pkg ast.Ref) TextEdit {
+	if len(module.Imports) == 0 {
+		return TextEdit{
+			Row:  module.Package.Location.Row + 1,
+			Col:  1,
+			Text: fmt.Sprintf("\nimport %s\n", pkg.String()),
+		}
+	}
+
+	lastImportedRow := 0
+	for _, imp := range module.Imports {
+		if lastImportedRow < imp.Location.Row {
+			lastImportedRow = imp.Location.Row
+		}
+	}
+	return TextEdit{
+		Row:  lastImportedRow + 1,
+		Col:  1,
+		Text: fmt.Sprintf("import %s\n", pkg.String()),
+	}
+}
+
 func isImported(p ast.Ref, imports []*ast.Import) bool {
 	for _, imp := range imports {
 		if p.Equal(imp.Path.Value) {
@@ -233,6 +255,16 @@ func (p *Project) listCompletionItemsInRule(loc *ast.Location, rule *ast.Rule) [
 			})
 		}
 
+		// a.b[x] := 1 if { ... }
+		for _, t := range rule.Head.Ref()[1:] {
+			if _, ok := t.Value.(ast.Var); ok {
+				result = append(result, CompletionItem{
+					Label: t.String(),
+					Kind:  VariableItem,
+				})
+			}
+		}
+
 		for _, arg := range rule.Head.Args {
 			result = append(result, CompletionItem{
 				Label: arg.String(),
@@ -245,22 +277,46 @@ func (p *Project) listCompletionItemsInRule(loc *ast.Location, rule *ast.Rule) [
 		if b.Loc().Row >= loc.Row {
 			break
 		}
+		result = append(result, p.listCompletionItemsInExpr(loc, b)...)
+	}
 
-		switch t := b.Terms.(type) {
-		case *ast.Term:
-			list := p.listCompletionItemsInTerm(loc, t)
-			result = append(result, list...)
-		case []*ast.Term:
-			if ast.Equality.Ref().Equal(b.Operator()) || ast.Assign.Ref().Equal(b.Operator()) {
-				list := p.listCompletionItemsInTerm(loc, t[1])
-				result = append(result, list...)
+	// The comprehension is often written in one line.
+	// [x | x := input[_]; x > |]
+	for _, s := range findScopes(loc, rule) {
+		for _, v := range s.vars {
+			result = append(result, p.listCompletionItemsInTerm(loc, v)...)
+		}
+		for _, b := range s.body {
+			if b.Loc().Offset+len(b.Loc().Text) >= loc.Offset {
+				break
 			}
+			result = append(result, p.listCompletionItemsInExpr(loc, b)...)
 		}
 	}
 
 	return result
 }
 
+func (p *Project) listCompletionItemsInExpr(loc *ast.Location, expr *ast.Expr) []CompletionItem {
+	switch t := expr.Terms.(type) {
+	case *ast.Term:
+		return p.listCompletionItemsInTerm(loc, t)
+	case []*ast.Term:
+		if ast.Equality.Ref().Equal(expr.Operator()) || ast.Assign.Ref().Equal(expr.Operator()) {
+			return p.listCompletionItemsInTerm(loc, t[1])
+		}
+	case *ast.SomeDecl:
+		result := make([]CompletionItem, 0)
+		for _, symbol := range t.Symbols {
+			for _, v := range someDeclVars(symbol) {
+				result = append(result, p.listCompletionItemsInTerm(loc, v)...)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
 func (p *Project) listCompletionItemsInTerm(loc *ast.Location, term *ast.Term) []CompletionItem {
 	result := make([]CompletionItem, 0)
 	switch v := term.Value.(type) {
@@ -308,13 +364,10 @@ func (p *Project) listRulesFromModules(location *ast.Location, modules []*ast.Mo
 	for _, m := range modules {
 		for _, r := range m.Rules {
 			item := createRuleCompletionItem(location, r)
-			alreadyItem, ok := exists[item.Label]
-			if !ok {
+			// The detail of the rules which have the same name is resolved together.
+			if _, ok := exists[item.Label]; !ok {
 				exists[item.Label] = item
-				continue
 			}
-			alreadyItem.Detail += "\n\n" + item.Detail
-			exists[alreadyItem.Label] = alreadyItem
 		}
 	}
 
@@ -340,8 +393,8 @@ func (p *Project) listBuiltinFunctions(location *ast.Location, term *ast.Term) [
 			result = append(result, CompletionItem{
 				Label:    b.Name,
 				Kind:     BuiltinFunctionItem,
-				Detail:   createDocForBuiltinFunction(b),
 				TextEdit: createTextEdit(location, fmt.Sprintf("%s%s", b.Name, b.Decl.FuncArgs().String())),
+				Data:     &CompletionData{Name: b.Name},
 			})
 		}
 		return result
@@ -357,8 +410,8 @@ func (p *Project) listBuiltinFunctions(location *ast.Location, term *ast.Term) [
 			result = append(result, CompletionItem{
 				Label:    name,
 				Kind:     BuiltinFunctionItem,
-				Detail:   createDocForBuiltinFunction(b),
 				TextEdit: createTextEdit(location, fmt.Sprintf("%s%s", name, b.Decl.FuncArgs().String())),
+				Data:     &CompletionData{Name: b.Name},
 			})
 		}
 	}
@@ -414,8 +467,10 @@ func isLibraryTerm(target *ast.Term) bool {
 
 func createRuleCompletionItem(location *ast.Location, rule *ast.Rule) CompletionItem {
 	head := rule.Head
+	ref := head.Ref()
+	label := ref.GroundPrefix().String()
 	var insertText strings.Builder
-	insertText.WriteString(head.Name.String())
+	insertText.WriteString(ref.String())
 	if len(rule.Head.Args) != 0 {
 		args := make([]string, len(rule.Head.Args))
 		for i, arg := range head.Args {
@@ -424,24 +479,28 @@ func createRuleCompletionItem(location *ast.Location, rule *ast.Rule) Completion
 		insertText.WriteByte('(')
 		insertText.WriteString(strings.Join(args, ", "))
 		insertText.WriteByte(')')
-	} else if head.Key != nil {
+	} else if head.Key != nil && len(ref) == 1 {
+		// deny contains msg -> deny[msg]
 		insertText.WriteByte('[')
 		insertText.WriteString(head.Key.String())
 		insertText.WriteByte(']')
 	}
 
 	var itemKind CompletionKind
-	if len(rule.Head.Args) != 0 || head.Key != nil {
+	if len(rule.Head.Args) != 0 || head.Key != nil || !ref.IsGround() {
 		itemKind = FunctionItem
 	} else {
 		itemKind = VariableItem
 	}
 
 	return CompletionItem{
-		Label:    rule.Head.Name.String(),
+		Label:    label,
 		Kind:     itemKind,
 		TextEdit: createTextEdit(location, insertText.String()),
-		Detail:   createDocForRule(rule),
+		Data: &CompletionData{
+			Package: rule.Module.Package.Path.String(),
+			Name:    label,
+		},
 	}
 }
 
@@ -496,12 +555,6 @@ func getTermPrefix(target *ast.Term) string {
 	}
 }
 
-func createDocForBuiltinFunction(builtin *ast.Builtin) string {
-	return fmt.Sprintf(`%s%s
-
-%s`, builtin.Name, builtin.Decl.FuncArgs().String(), BuiltinDetail)
-}
-
 func createTextEdit(location *ast.Location, text string) *TextEdit {
 	return &TextEdit{
 		Row:  location.Row,
@@ -0,0 +1,77 @@
+package source
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// ResolveCompletionItem fills the detail and the documentation of the completion item.
+// The completion list doesn't have them, so that the list stays light.
+//
+//	rule              -> the source, the METADATA annotations or the comments, and the defining file
+//	built-in function -> the signature, the description, the arguments, the result, the categories and the link
+func (p *Project) ResolveCompletionItem(item CompletionItem) CompletionItem {
+	if item.Data == nil {
+		return item
+	}
+
+	if item.Data.Package == "" {
+		b, ok := ast.BuiltinMap[item.Data.Name]
+		if !ok {
+			return item
+		}
+		item.Detail = builtinSignature(b)
+		item.Documentation = builtinDocumentation(b)
+		return item
+	}
+
+	pkg, err := ast.ParseRef(item.Data.Package)
+	if err != nil {
+		return item
+	}
+
+	rules := make([]*ast.Rule, 0)
+	for _, m := range p.cache.FindPolicies(pkg) {
+		for _, r := range m.Rules {
+			if r.Head.Ref().GroundPrefix().String() == item.Data.Name {
+				rules = append(rules, r)
+			}
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Location.File != rules[j].Location.File {
+			return rules[i].Location.File < rules[j].Location.File
+		}
+		return rules[i].Location.Row < rules[j].Location.Row
+	})
+
+	details := make([]string, 0, len(rules))
+	docs := make([]string, 0, len(rules))
+	for _, r := range rules {
+		details = append(details, createDocForRule(r))
+
+		doc := fmt.Sprintf("*Defined in %s:%d*", p.relativePath(r.Location.File), r.Location.Row)
+		if d := ruleDocumentation(r); d != "" {
+			doc = d + "\n\n" + doc
+		}
+		docs = append(docs, doc)
+	}
+	item.Detail = strings.Join(details, "\n\n")
+	item.Documentation = strings.Join(docs, "\n\n---\n\n")
+	return item
+}
+
+// relativePath returns the path relative to the workspace folder which contains it.
+func (p *Project) relativePath(path string) string {
+	for _, r := range p.RootPaths() {
+		rel, err := filepath.Rel(r, path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
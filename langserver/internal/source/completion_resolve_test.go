@@ -0,0 +1,146 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+)
+
+func TestProject_ResolveCompletionItem(t *testing.T) {
+	tests := map[string]struct {
+		files  map[string]source.File
+		item   source.CompletionItem
+		expect source.CompletionItem
+	}{
+		"Should resolve rule with the comments": {
+			files: map[string]source.File{
+				"lib.rego": {
+					RawText: `package lib
+
+# is_hello returns true when the msg is hello.
+is_hello(msg) {
+	msg == "hello"
+}`,
+				},
+			},
+			item: source.CompletionItem{
+				Label: "is_hello",
+				Kind:  source.FunctionItem,
+				Data:  &source.CompletionData{Package: "data.lib", Name: "is_hello"},
+			},
+			expect: source.CompletionItem{
+				Label: "is_hello",
+				Kind:  source.FunctionItem,
+				Detail: `is_hello(msg) {
+	msg == "hello"
+}`,
+				Documentation: "is_hello returns true when the msg is hello.\n\n*Defined in lib.rego:4*",
+				Data:          &source.CompletionData{Package: "data.lib", Name: "is_hello"},
+			},
+		},
+		"Should resolve rule which is defined in multiple files": {
+			files: map[string]source.File{
+				"a.rego": {
+					RawText: `package lib
+
+# METADATA
+# title: Allow
+allow {
+	input.admin
+}`,
+				},
+				"b.rego": {
+					RawText: `package lib
+
+allow {
+	input.owner
+}`,
+				},
+			},
+			item: source.CompletionItem{
+				Label: "allow",
+				Kind:  source.VariableItem,
+				Data:  &source.CompletionData{Package: "data.lib", Name: "allow"},
+			},
+			expect: source.CompletionItem{
+				Label: "allow",
+				Kind:  source.VariableItem,
+				Detail: `allow {
+	input.admin
+}
+
+allow {
+	input.owner
+}`,
+				Documentation: "### Allow\n\n*Scope: rule*\n\n*Defined in a.rego:5*\n\n---\n\n*Defined in b.rego:3*",
+				Data:          &source.CompletionData{Package: "data.lib", Name: "allow"},
+			},
+		},
+		"Should resolve built-in function": {
+			item: source.CompletionItem{
+				Label: "sprintf",
+				Kind:  source.BuiltinFunctionItem,
+				Data:  &source.CompletionData{Name: "sprintf"},
+			},
+			expect: source.CompletionItem{
+				Label:  "sprintf",
+				Kind:   source.BuiltinFunctionItem,
+				Detail: "sprintf(format: string, values: array[any]) => output: string",
+				Documentation: "Returns the given string, formatted.\n\n" +
+					"**Arguments**\n\n" +
+					"- `format: string`: string with formatting verbs\n" +
+					"- `values: array[any]`: arguments to format into formatting verbs\n\n" +
+					"**Returns**\n\n" +
+					"- `output: string`: `format` formatted by the values in `values`\n\n" +
+					"*Categories: strings*\n\n" +
+					"[Policy reference](https://www.openpolicyagent.org/docs/latest/policy-reference/#builtin-strings-sprintf)",
+				Data: &source.CompletionData{Name: "sprintf"},
+			},
+		},
+		"Should resolve deprecated built-in function": {
+			item: source.CompletionItem{
+				Label: "re_match",
+				Kind:  source.BuiltinFunctionItem,
+				Data:  &source.CompletionData{Name: "re_match"},
+			},
+			expect: source.CompletionItem{
+				Label:  "re_match",
+				Kind:   source.BuiltinFunctionItem,
+				Detail: "re_match(string, string) => boolean",
+				Documentation: "**Deprecated**\n\n" +
+					"**Arguments**\n\n" +
+					"- `string`\n" +
+					"- `string`\n\n" +
+					"**Returns**\n\n" +
+					"- `boolean`\n\n" +
+					"[Policy reference](https://www.openpolicyagent.org/docs/latest/policy-reference/#built-in-functions)",
+				Data: &source.CompletionData{Name: "re_match"},
+			},
+		},
+		"Should not resolve item without data": {
+			item: source.CompletionItem{
+				Label: "msg",
+				Kind:  source.VariableItem,
+			},
+			expect: source.CompletionItem{
+				Label: "msg",
+				Kind:  source.VariableItem,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			project, err := source.NewProjectWithFiles(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := project.ResolveCompletionItem(tt.item)
+			if diff := cmp.Diff(tt.expect, got); diff != "" {
+				t.Errorf("ResolveCompletionItem result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
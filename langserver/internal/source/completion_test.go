@@ -130,9 +130,34 @@ mem_multiple("P") = 1000000000000000000`,
 						Col:  2,
 						Text: `mem_multiple("E")`,
 					},
-					Detail: `mem_multiple("E") = 1000000000000000000000
+					Data: &source.CompletionData{Package: "data.src", Name: "mem_multiple"},
+				},
+			},
+		},
+		"Should list ref head rule with its full path": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
 
-mem_multiple("P") = 1000000000000000000`,
+import rego.v1
+
+allow if {
+	serv|
+}
+
+servers.web.port := 80`,
+				},
+			},
+			expectItems: []source.CompletionItem{
+				{
+					Label: "servers.web.port",
+					Kind:  source.VariableItem,
+					TextEdit: &source.TextEdit{
+						Row:  6,
+						Col:  2,
+						Text: "servers.web.port",
+					},
+					Data: &source.CompletionData{Package: "data.src", Name: "servers.web.port"},
 				},
 			},
 		},
@@ -373,9 +398,7 @@ is_hello(msg) {
 							Col:  2,
 							Text: "is_hello(msg)",
 						},
-						Detail: `is_hello(msg) {
-	msg == "hello"
-}`,
+						Data: &source.CompletionData{Package: "data.main", Name: "is_hello"},
 					},
 				},
 			},
@@ -405,9 +428,7 @@ hello(msg) {
 							Col:  2,
 							Text: "hello(msg)",
 						},
-						Detail: `hello(msg) {
-	msg == "hello"
-}`,
+						Data: &source.CompletionData{Package: "data.main", Name: "hello"},
 					},
 				},
 			},
@@ -439,9 +460,7 @@ is_hello(msg) {
 							Col:  6,
 							Text: "is_hello(msg)",
 						},
-						Detail: `is_hello(msg) {
-	msg == "hello"
-}`,
+						Data: &source.CompletionData{Package: "data.lib", Name: "is_hello"},
 					},
 				},
 			},
@@ -457,9 +476,9 @@ violation[msg] {
 				},
 				expectItems: []source.CompletionItem{
 					{
-						Label:  "json.patch",
-						Kind:   source.BuiltinFunctionItem,
-						Detail: "json.patch(any, array[object<op: string, path: any>[any: any]])\n\n" + source.BuiltinDetail,
+						Label: "json.patch",
+						Kind:  source.BuiltinFunctionItem,
+						Data:  &source.CompletionData{Name: "json.patch"},
 						TextEdit: &source.TextEdit{
 							Row:  4,
 							Col:  2,
@@ -480,9 +499,9 @@ violation[msg] {
 				},
 				expectItems: []source.CompletionItem{
 					{
-						Label:  "patch",
-						Kind:   source.BuiltinFunctionItem,
-						Detail: "json.patch(any, array[object<op: string, path: any>[any: any]])\n\n" + source.BuiltinDetail,
+						Label: "patch",
+						Kind:  source.BuiltinFunctionItem,
+						Data:  &source.CompletionData{Name: "json.patch"},
 						TextEdit: &source.TextEdit{
 							Row:  4,
 							Col:  7,
@@ -512,7 +531,7 @@ default is_test = true`,
 							Col:  2,
 							Text: "is_test",
 						},
-						Detail: "default is_test = true",
+						Data: &source.CompletionData{Package: "data.src", Name: "is_test"},
 					},
 				},
 			},
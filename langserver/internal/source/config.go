@@ -0,0 +1,258 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kitagry/regols/langserver/internal/cache"
+	"github.com/open-policy-agent/opa/ast"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFileName is the name of the project configuration file in the workspace folder.
+const ConfigFileName = ".regols.yaml"
+
+// IgnoreFileNames is the names of the files which have the ignore patterns in the gitignore format.
+var IgnoreFileNames = cache.IgnoreFileNames
+
+// Config is the project configuration which is checked into the repository.
+// The relative paths are resolved from the workspace folder.
+//
+//	ignore:
+//	  - vendor
+//	  - "**/testdata/**"
+//	regoVersion: v1
+//	data:
+//	  - data
+//	schemas:
+//	  - schemas
+//	inputSchemas:
+//	  policies/admission/**: schema.admission_review
+//	capabilities: capabilities.json
+//	lint:
+//	  unused-import: warning
+//	  deprecated-builtin: "off"
+//	input:
+//	  - examples/input.json
+type Config struct {
+	// Ignore is the gitignore style patterns of the paths which are not loaded.
+	// e.g. "vendor/" ignores the vendor directories at any level, "/build" only in the workspace folder,
+	// and "!keep.rego" re-includes the files which are ignored by the previous patterns.
+	Ignore []string `json:"ignore,omitempty"`
+	// RegoVersion is the rego version of the policies. "auto", "v0", "v0-future-keywords" or "v1".
+	RegoVersion string `json:"regoVersion,omitempty"`
+	// Data is the root directories of the data documents in the workspace folder.
+	// The workspace folder is the root directory by default.
+	Data []string `json:"data,omitempty"`
+	// Schemas is the directories of the JSON schemas.
+	// The schema is referred by the path relative to the directory. e.g. k8s/pod.json -> schema.k8s.pod
+	Schemas []string `json:"schemas,omitempty"`
+	// InputSchemas is the input schema of the policies. The key is the gitignore style pattern of the policies
+	// and the value is the ref of the schema. The METADATA schemas take precedence over it.
+	InputSchemas map[string]string `json:"inputSchemas,omitempty"`
+	// Capabilities is the OPA capabilities file which is used to compile the policies.
+	Capabilities string `json:"capabilities,omitempty"`
+	// Lint is the severity of each lint check. "error", "warning", "information", "hint" or "off".
+	Lint map[string]string `json:"lint,omitempty"`
+	// Input is the sample input documents. They are read when the configuration is loaded.
+	Input []string `json:"input,omitempty"`
+
+	capabilities *ast.Capabilities
+	schemas      []schema
+	inputSchemas map[string]ast.Ref
+	inputs       []any
+}
+
+// loadConfig reads the configuration file in the root path.
+// When the file doesn't exist, it returns the empty config.
+func loadConfig(rootPath string) (*Config, error) {
+	b, err := os.ReadFile(filepath.Join(rootPath, ConfigFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ConfigFileName, err)
+	}
+	c.resolvePaths(rootPath)
+
+	for check, severity := range c.Lint {
+		if _, err := ParseLintSeverity(severity); err != nil {
+			return nil, fmt.Errorf("invalid lint %s: %w", check, err)
+		}
+	}
+
+	c.schemas, err = loadSchemas(c.Schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schemas: %w", err)
+	}
+	c.inputs = loadSampleInputs(c.Input)
+
+	for pattern, s := range c.InputSchemas {
+		if c.inputSchemas == nil {
+			c.inputSchemas = make(map[string]ast.Ref, len(c.InputSchemas))
+		}
+		ref, err := ast.ParseRef(s)
+		if err != nil || !ref.HasPrefix(ast.SchemaRootRef) {
+			return nil, fmt.Errorf("invalid input schema %q of %s", s, pattern)
+		}
+		c.inputSchemas[pattern] = ref
+	}
+
+	if c.Capabilities != "" {
+		c.capabilities, err = ast.LoadCapabilitiesFile(c.Capabilities)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load capabilities: %w", err)
+		}
+	}
+	return &c, nil
+}
+
+// resolvePaths resolves the relative paths from the root path.
+func (c *Config) resolvePaths(rootPath string) {
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(rootPath, p)
+	}
+
+	for i, d := range c.Data {
+		c.Data[i] = resolve(d)
+	}
+	for i, s := range c.Schemas {
+		c.Schemas[i] = resolve(s)
+	}
+	for i, in := range c.Input {
+		c.Input[i] = resolve(in)
+	}
+	c.Capabilities = resolve(c.Capabilities)
+}
+
+// Config returns the configuration of the workspace folder which contains the path.
+// When the path is not in any workspace folder, it returns the empty config.
+func (p *Project) Config(path string) *Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.config(path)
+}
+
+// config returns the configuration of the path. The caller should hold the lock.
+func (p *Project) config(path string) *Config {
+	var (
+		result  *Config
+		longest int
+	)
+	for root, c := range p.configs {
+		if isUnder(path, root) && len(root) > longest {
+			result, longest = c, len(root)
+		}
+	}
+	if result == nil {
+		return &Config{}
+	}
+	return result
+}
+
+// ConfigError is the error of the configuration file. The workspace folder falls back to the default configuration.
+type ConfigError struct {
+	RootPath string
+	Err      error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", filepath.Join(e.RootPath, ConfigFileName), e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// applyConfig loads the configuration file in the root path and applies it to the cache.
+// When the configuration file is invalid, the default configuration is applied and the ConfigError is returned.
+// The caller should hold the lock.
+func (p *Project) applyConfig(rootPath string) error {
+	c, configErr := loadConfig(rootPath)
+	var version RegoVersion
+	if configErr == nil {
+		version, configErr = ParseRegoVersion(c.RegoVersion)
+	}
+	if configErr != nil {
+		c, version = &Config{}, RegoVersionAuto
+		configErr = &ConfigError{RootPath: rootPath, Err: configErr}
+	}
+
+	p.configs[rootPath] = c
+	p.cache.SetIgnorePatterns(rootPath, c.Ignore)
+	// The data documents are put under the path relative to the workspace folder by default.
+	dataRoots := c.Data
+	if len(dataRoots) == 0 {
+		dataRoots = []string{rootPath}
+	}
+	p.cache.SetDataRoots(rootPath, dataRoots)
+	p.cache.SetCapabilities(p.capabilities())
+	p.cache.SetSchemaSet(p.schemaSet())
+	p.cache.SetInputSchemas(rootPath, c.inputSchemas)
+	if err := p.cache.SetRegoVersion(rootPath, version); err != nil {
+		return err
+	}
+	return configErr
+}
+
+// capabilities returns the capabilities of the first workspace folder which configures it.
+// The caller should hold the lock.
+func (p *Project) capabilities() *ast.Capabilities {
+	for _, r := range p.rootPaths {
+		if c, ok := p.configs[r]; ok && c.capabilities != nil {
+			return c.capabilities
+		}
+	}
+	return nil
+}
+
+// ReloadConfig reloads the configuration file and the ignore files of the workspace folder, and the files under it.
+// It returns the files which are removed by the new configuration.
+// When the configuration file is invalid, the files are reloaded with the default configuration and the ConfigError is returned.
+func (p *Project) ReloadConfig(rootPath string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	configErr := p.applyConfig(rootPath)
+	if !isConfigError(configErr) {
+		return nil, configErr
+	}
+
+	deleted := p.cache.DeleteDir(rootPath)
+	p.cache.ClearIgnoreFiles(rootPath)
+	if err := p.cache.LoadDir(rootPath); err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0)
+	for _, path := range deleted {
+		if p.cache.Get(path) == nil {
+			removed = append(removed, path)
+		}
+	}
+	return removed, configErr
+}
+
+// isConfigError returns true when err is nil or the ConfigError.
+func isConfigError(err error) bool {
+	var configErr *ConfigError
+	return err == nil || errors.As(err, &configErr)
+}
+
+// isUnder returns true when the path is the directory or is contained in it.
+func isUnder(path, dir string) bool {
+	dir = strings.TrimSuffix(dir, string(filepath.Separator))
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,424 @@
+package source_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/kitagry/regols/langserver/internal/source"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, text := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewProject_Config(t *testing.T) {
+	tests := map[string]struct {
+		files        map[string]string
+		expectLoaded map[string]bool
+		expectConfig *source.Config
+	}{
+		"Should not load ignored files": {
+			files: map[string]string{
+				source.ConfigFileName:            "ignore:\n  - vendor\n  - \"**/testdata/**\"\n",
+				"src.rego":                       "package src",
+				"vendor/lib.rego":                "package lib",
+				"policies/testdata/broken.rego":  "package",
+				"policies/testdata.rego":         "package testdata",
+				"policies/sub/testdata/a/b.rego": "package b",
+			},
+			expectLoaded: map[string]bool{
+				"src.rego":                       true,
+				"vendor/lib.rego":                false,
+				"policies/testdata/broken.rego":  false,
+				"policies/testdata.rego":         true,
+				"policies/sub/testdata/a/b.rego": false,
+			},
+			expectConfig: &source.Config{
+				Ignore: []string{"vendor", "**/testdata/**"},
+			},
+		},
+		"Should parse with the rego version": {
+			files: map[string]string{
+				source.ConfigFileName: "regoVersion: v1\ninput:\n  - input.json\n",
+				"src.rego":            "package src\n\nallow if input.admin\n",
+			},
+			expectLoaded: map[string]bool{
+				"src.rego": true,
+			},
+			expectConfig: &source.Config{
+				RegoVersion: "v1",
+				Input:       []string{"input.json"},
+			},
+		},
+		"Should load all files without config": {
+			files: map[string]string{
+				"src.rego":        "package src",
+				"vendor/lib.rego": "package lib",
+			},
+			expectLoaded: map[string]bool{
+				"src.rego":        true,
+				"vendor/lib.rego": true,
+			},
+			expectConfig: &source.Config{},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			project, err := source.NewProject(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for path, expect := range tt.expectLoaded {
+				path = filepath.Join(dir, path)
+				if _, ok := project.GetFile(path); ok != expect {
+					t.Errorf("GetFile(%s) expect %v, but got %v", path, expect, ok)
+				}
+				if expect && project.GetModule(path) == nil {
+					t.Errorf("%s should be parsed", path)
+				}
+			}
+
+			for i, in := range tt.expectConfig.Input {
+				tt.expectConfig.Input[i] = filepath.Join(dir, in)
+			}
+			got := project.Config(filepath.Join(dir, "src.rego"))
+			if diff := cmp.Diff(tt.expectConfig, got, cmp.AllowUnexported(source.Config{})); diff != "" {
+				t.Errorf("Config result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewProject_InvalidConfig(t *testing.T) {
+	tests := map[string]struct {
+		config string
+	}{
+		"Should fall back with the YAML syntax error": {
+			config: "ignore: [vendor\n",
+		},
+		"Should fall back with the unknown lint severity": {
+			config: "lint:\n  unused-import: warn\n",
+		},
+		"Should fall back with the missing schemas directory": {
+			config: "schemas:\n  - not_found\n",
+		},
+		"Should fall back with the invalid input schema": {
+			config: "inputSchemas:\n  \"**\": data.schema\n",
+		},
+		"Should fall back with the missing capabilities file": {
+			config: "capabilities: not_found.json\n",
+		},
+		"Should fall back with the unknown rego version": {
+			config: "regoVersion: v2\n",
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				source.ConfigFileName: tt.config,
+				"src.rego":            "package src",
+			})
+
+			project, err := source.NewProject(dir)
+			var configErr *source.ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("NewProject should return ConfigError, but got %v", err)
+			}
+			if configErr.RootPath != dir {
+				t.Errorf("ConfigError.RootPath expect %s, but got %s", dir, configErr.RootPath)
+			}
+
+			path := filepath.Join(dir, "src.rego")
+			if project.GetModule(path) == nil {
+				t.Errorf("%s should be loaded with the default config", path)
+			}
+			if diff := cmp.Diff(&source.Config{}, project.Config(path), cmp.AllowUnexported(source.Config{})); diff != "" {
+				t.Errorf("Config result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProject_AddRootPathWithInvalidConfig(t *testing.T) {
+	policies, lib := t.TempDir(), t.TempDir()
+	writeFiles(t, lib, map[string]string{
+		source.ConfigFileName: "lint:\n  unused-import: warn\n",
+		"lib.rego":            "package lib",
+	})
+
+	project, err := source.NewProject(policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var configErr *source.ConfigError
+	if err := project.AddRootPath(lib); !errors.As(err, &configErr) {
+		t.Fatalf("AddRootPath should return ConfigError, but got %v", err)
+	}
+	if _, ok := project.GetFile(filepath.Join(lib, "lib.rego")); !ok {
+		t.Errorf("lib.rego should be loaded with the default config")
+	}
+	if diff := cmp.Diff([]string{policies, lib}, project.RootPaths()); diff != "" {
+		t.Errorf("RootPaths result diff (-expect, +got)\n%s", diff)
+	}
+}
+
+func TestProject_Lint(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		source.ConfigFileName: "lint:\n  unused-import: warning\n  unused-variable: \"off\"\n",
+		"src.rego":            "package src\n\nimport data.lib\n\nallow {\n\tx := 1\n\tinput.admin\n}\n",
+		"lib.rego":            "package lib\n",
+	})
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "src.rego")
+	got := make(map[string][]string)
+	for path, issues := range project.Lint(project.GetErrors(path)) {
+		for _, issue := range issues {
+			got[path] = append(got[path], issue.Check)
+			if issue.Severity != source.LintWarning {
+				t.Errorf("%s severity expect warning, but got %v", issue.Check, issue.Severity)
+			}
+		}
+	}
+	expect := map[string][]string{
+		path: {source.UnusedImportCheck},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("Lint result diff (-expect, +got)\n%s", diff)
+	}
+}
+
+func TestProject_ReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src.rego":          "package src",
+		"fixture/bad.rego":  "package src\n\nallow {",
+		"fixture/good.rego": "package fixture",
+	})
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, dir, map[string]string{
+		source.ConfigFileName: "ignore:\n  - fixture\n",
+	})
+	removed, err := project.ReloadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{filepath.Join(dir, "fixture/bad.rego"), filepath.Join(dir, "fixture/good.rego")}
+	if diff := cmp.Diff(expect, removed, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("ReloadConfig result diff (-expect, +got)\n%s", diff)
+	}
+	if _, ok := project.GetFile(filepath.Join(dir, "src.rego")); !ok {
+		t.Errorf("src.rego should be loaded")
+	}
+}
+
+func TestProject_LintOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		source.ConfigFileName: "lint:\n  unused-argument: hint\n",
+		"src.rego":            "package src\n\nf(a) {\n\tx := 1\n\tinput.admin\n}\n",
+	})
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project.SetLintOptions(source.LintOptions{
+		Strict:     true,
+		Severities: map[string]source.LintSeverity{source.UnusedArgumentCheck: source.LintWarning},
+	})
+
+	path := filepath.Join(dir, "src.rego")
+	got := make(map[string]source.LintSeverity)
+	for _, issue := range project.Lint(project.GetErrors(path))[path] {
+		got[issue.Check] = issue.Severity
+	}
+	expect := map[string]source.LintSeverity{
+		source.UnusedArgumentCheck: source.LintHint,
+		source.UnusedVariableCheck: source.LintError,
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("Lint result diff (-expect, +got)\n%s", diff)
+	}
+}
+
+func TestProject_LintWithErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src.rego": "package src\n\nimport data.lib\n\nallow {\n\tundefined_func(input.admin)\n}\n",
+	})
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project.SetLintOptions(source.LintOptions{Strict: true})
+
+	if got := project.Lint(project.GetErrors(filepath.Join(dir, "src.rego"))); len(got) != 0 {
+		t.Errorf("Lint should not report issues when the policies have errors, but got %v", got)
+	}
+}
+
+func TestNewProject_IgnoreFiles(t *testing.T) {
+	tests := map[string]struct {
+		files        map[string]string
+		expectLoaded map[string]bool
+	}{
+		"Should not load the files in .gitignore": {
+			files: map[string]string{
+				".gitignore":            "# build outputs\nbuild/\nfixtures/*\n!fixtures/keep.rego\n",
+				"src.rego":              "package src",
+				"build/out.rego":        "package out",
+				"fixtures/broken.rego":  "package",
+				"fixtures/keep.rego":    "package keep",
+				"policies/build.rego":   "package build",
+				".git/objects/obj.rego": "package obj",
+			},
+			expectLoaded: map[string]bool{
+				"src.rego":              true,
+				"build/out.rego":        false,
+				"fixtures/broken.rego":  false,
+				"fixtures/keep.rego":    true,
+				"policies/build.rego":   true,
+				".git/objects/obj.rego": false,
+			},
+		},
+		"Should apply the nested ignore files": {
+			files: map[string]string{
+				".regolsignore":          "*_fixture.rego\n",
+				"src.rego":               "package src",
+				"src_fixture.rego":       "package fixture",
+				"sub/.gitignore":         "/generated\n!keep_fixture.rego\n",
+				"sub/generated/a.rego":   "package a",
+				"sub/a/generated/b.rego": "package b",
+				"sub/keep_fixture.rego":  "package keep",
+				"sub/lib_fixture.rego":   "package lib",
+			},
+			expectLoaded: map[string]bool{
+				"src.rego":               true,
+				"src_fixture.rego":       false,
+				"sub/generated/a.rego":   false,
+				"sub/a/generated/b.rego": true,
+				"sub/keep_fixture.rego":  true,
+				"sub/lib_fixture.rego":   false,
+			},
+		},
+		"Should prefer the config patterns to the ignore files": {
+			files: map[string]string{
+				source.ConfigFileName: "ignore:\n  - \"!keep_fixture.rego\"\n",
+				".gitignore":          "*_fixture.rego\n",
+				"keep_fixture.rego":   "package keep",
+				"lib_fixture.rego":    "package lib",
+			},
+			expectLoaded: map[string]bool{
+				"keep_fixture.rego": true,
+				"lib_fixture.rego":  false,
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			project, err := source.NewProject(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for path, expect := range tt.expectLoaded {
+				path = filepath.Join(dir, path)
+				if _, ok := project.GetFile(path); ok != expect {
+					t.Errorf("GetFile(%s) expect %v, but got %v", path, expect, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestNewProject_NestedRootIgnore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		source.ConfigFileName:          "ignore:\n  - \"*.rego\"\n",
+		"sub/" + source.ConfigFileName: "ignore:\n  - \"!keep.rego\"\n",
+		"src.rego":                     "package src",
+		"sub/keep.rego":                "package keep",
+	})
+
+	// The deeper workspace folder takes precedence regardless of the order.
+	for _, roots := range [][]string{{dir, filepath.Join(dir, "sub")}, {filepath.Join(dir, "sub"), dir}} {
+		project, err := source.NewProject(roots...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := project.GetFile(filepath.Join(dir, "src.rego")); ok {
+			t.Errorf("src.rego should be ignored with the roots %v", roots)
+		}
+		if _, ok := project.GetFile(filepath.Join(dir, "sub/keep.rego")); !ok {
+			t.Errorf("sub/keep.rego should be loaded with the roots %v", roots)
+		}
+	}
+}
+
+func TestProject_ReloadConfig_IgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src.rego":         "package src",
+		"sub/fixture.rego": "package src\n\nallow {",
+		"sub/policy.rego":  "package policy",
+	})
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"sub/.gitignore": "fixture.rego\n",
+	})
+	removed, err := project.ReloadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{filepath.Join(dir, "sub/fixture.rego")}
+	if diff := cmp.Diff(expect, removed); diff != "" {
+		t.Errorf("ReloadConfig result diff (-expect, +got)\n%s", diff)
+	}
+	if _, ok := project.GetFile(filepath.Join(dir, "sub/policy.rego")); !ok {
+		t.Errorf("sub/policy.rego should be loaded")
+	}
+}
@@ -0,0 +1,239 @@
+package source
+
+import (
+	"encoding/json"
+
+	"github.com/kitagry/regols/langserver/internal/cache"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// IsDataFile returns true when the file is the JSON or YAML data document. e.g. data.json, roles/data.yaml
+func IsDataFile(path string) bool {
+	return cache.IsDataFile(path)
+}
+
+// maxDataDetailLength is the max length of the value in the completion detail.
+const maxDataDetailLength = 80
+
+// dataRef returns the ref of the data document which the term refers.
+// The imported package is resolved.
+//
+//	import data.roles
+//	roles.admin -> data.roles.admin
+func (p *Project) dataRef(term *ast.Term) ast.Ref {
+	return p.rootDocumentRef(term, ast.DefaultRootDocument)
+}
+
+// rootDocumentRef returns the ref from the root document. e.g. data, input
+// It returns nil when the term doesn't refer the root document.
+func (p *Project) rootDocumentRef(term *ast.Term, root *ast.Term) ast.Ref {
+	ref, ok := term.Value.(ast.Ref)
+	if !ok || len(ref) == 0 {
+		return nil
+	}
+	if root.Equal(ref[0]) {
+		return ref
+	}
+
+	module := p.GetModule(term.Loc().File)
+	if module == nil {
+		return nil
+	}
+	for _, imp := range module.Imports {
+		path, ok := imp.Path.Value.(ast.Ref)
+		if !ok || len(path) < 2 || !root.Equal(path[0]) {
+			continue
+		}
+		name := imp.Alias
+		if name == "" {
+			s, ok := path[len(path)-1].Value.(ast.String)
+			if !ok {
+				continue
+			}
+			name = ast.Var(s)
+		}
+		if name.Equal(ref[0].Value) {
+			return path.Concat(ref[1:])
+		}
+	}
+	return nil
+}
+
+// findDataTerm returns the key and the value of the ref in the data document.
+// The key is nil when the ref is the path of the document.
+func findDataTerm(doc *cache.DataDocument, ref ast.Ref) (key, value *ast.Term, ok bool) {
+	if doc.Value == nil || !ref.HasPrefix(doc.Path) {
+		return nil, nil, false
+	}
+
+	value = doc.Value
+	for _, t := range ref[len(doc.Path):] {
+		switch v := value.Value.(type) {
+		case ast.Object:
+			key = nil
+			for _, k := range v.Keys() {
+				if k.Value.Compare(t.Value) == 0 {
+					key = k
+					break
+				}
+			}
+			if key == nil {
+				return nil, nil, false
+			}
+			value = v.Get(key)
+		case *ast.Array:
+			n, ok := t.Value.(ast.Number)
+			if !ok {
+				return nil, nil, false
+			}
+			i, ok := n.Int()
+			if !ok || i < 0 || i >= v.Len() {
+				return nil, nil, false
+			}
+			value = v.Elem(i)
+			key = value
+		default:
+			return nil, nil, false
+		}
+	}
+	return key, value, true
+}
+
+// findDataDefinitions returns the locations of the keys in the data documents.
+// When the ref is the path of the document or its parent, it returns the head of the document.
+func (p *Project) findDataDefinitions(ref ast.Ref) []*ast.Location {
+	result := make([]*ast.Location, 0)
+	for _, doc := range p.cache.GetDataDocuments() {
+		if len(doc.Path) > len(ref) && doc.Path.HasPrefix(ref) {
+			result = append(result, dataDocumentLocation(doc))
+			continue
+		}
+
+		key, _, ok := findDataTerm(doc, ref)
+		if !ok {
+			continue
+		}
+		if key == nil || key.Loc() == nil {
+			result = append(result, dataDocumentLocation(doc))
+			continue
+		}
+		result = append(result, key.Loc())
+	}
+	return result
+}
+
+// dataDocumentLocation returns the location of the first character of the document.
+func dataDocumentLocation(doc *cache.DataDocument) *ast.Location {
+	loc := &ast.Location{Row: 1, Col: 1}
+	if doc.Value != nil && doc.Value.Loc() != nil {
+		loc.File = doc.Value.Loc().File
+		loc.Row = doc.Value.Loc().Row
+		loc.Col = doc.Value.Loc().Col
+		loc.Offset = doc.Value.Loc().Offset
+	}
+	if loc.Offset < len(doc.RawText) {
+		loc.Text = []byte(doc.RawText[loc.Offset : loc.Offset+1])
+	}
+	return loc
+}
+
+// findDataDocuments returns the values of the ref in the data documents.
+func (p *Project) findDataDocuments(ref ast.Ref) []Document {
+	result := make([]Document, 0)
+	for _, doc := range p.cache.GetDataDocuments() {
+		_, value, ok := findDataTerm(doc, ref)
+		if !ok {
+			continue
+		}
+		b, err := marshalDataValue(value)
+		if err != nil {
+			continue
+		}
+		result = append(result, Document{
+			Content:  string(b),
+			Language: "json",
+		})
+	}
+	return result
+}
+
+// listDataCompletionItems lists the keys under the ref in the data documents.
+//
+//	data.roles.| -> admin, viewer
+func (p *Project) listDataCompletionItems(location *ast.Location, target *ast.Term) []CompletionItem {
+	if target == nil {
+		return nil
+	}
+	ref := p.dataRef(target)
+	if len(ref) < 2 {
+		return nil
+	}
+	parent := ref[:len(ref)-1]
+
+	result := make([]CompletionItem, 0)
+	for _, doc := range p.cache.GetDataDocuments() {
+		// data.| -> roles (roles/data.json)
+		if len(doc.Path) > len(parent) && doc.Path.HasPrefix(parent) {
+			if s, ok := doc.Path[len(parent)].Value.(ast.String); ok {
+				result = append(result, CompletionItem{
+					Label:    string(s),
+					Kind:     FieldItem,
+					TextEdit: createTextEdit(location, string(s)),
+				})
+			}
+			continue
+		}
+
+		_, value, ok := findDataTerm(doc, parent)
+		if !ok {
+			continue
+		}
+		obj, ok := value.Value.(ast.Object)
+		if !ok {
+			continue
+		}
+		for _, k := range obj.Keys() {
+			s, ok := k.Value.(ast.String)
+			if !ok {
+				continue
+			}
+			result = append(result, CompletionItem{
+				Label:    string(s),
+				Kind:     FieldItem,
+				Detail:   dataDetail(obj.Get(k)),
+				TextEdit: createTextEdit(location, string(s)),
+			})
+		}
+	}
+	return result
+}
+
+// dataDetail returns the short JSON of the value.
+func dataDetail(value *ast.Term) string {
+	v, err := ast.JSON(value.Value)
+	if err != nil {
+		return ""
+	}
+	return jsonDetail(v)
+}
+
+// jsonDetail returns the short JSON of the value for the completion detail.
+func jsonDetail(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	if len(b) > maxDataDetailLength {
+		return string(b[:maxDataDetailLength]) + "..."
+	}
+	return string(b)
+}
+
+// marshalDataValue returns the indented JSON of the value for the hover.
+func marshalDataValue(value *ast.Term) ([]byte, error) {
+	v, err := ast.JSON(value.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
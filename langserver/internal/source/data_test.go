@@ -0,0 +1,213 @@
+package source_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/kitagry/regols/langserver/internal/source/helper"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// newProjectOnDisk writes the files to the temporary directory and loads them.
+// The location of the cursor is resolved in the directory.
+func newProjectOnDisk(t *testing.T, files map[string]source.File) (*source.Project, *ast.Location, string) {
+	t.Helper()
+
+	files, location, err := helper.GetAstLocation(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	texts := make(map[string]string, len(files))
+	for path, f := range files {
+		texts[path] = f.RawText
+	}
+	writeFiles(t, dir, texts)
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	location.File = filepath.Join(dir, location.File)
+	return project, location, dir
+}
+
+var dataTestFiles = map[string]source.File{
+	"roles/data.json":  {RawText: `{"admin": ["alice"], "viewer": []}`},
+	"config/data.yaml": {RawText: "limits:\n  cpu: 2\n  \"memory\": 1Gi\n"},
+	"i18n/data.yaml":   {RawText: "messages: {\"挨拶\": hello, \"farewell\": bye}\n"},
+}
+
+func TestProject_LookupDefinition_Data(t *testing.T) {
+	tests := map[string]struct {
+		src          string
+		expectResult []*ast.Location
+	}{
+		"Should return the key in the JSON document": {
+			src: `package main
+
+allow {
+	data.roles.ad|min[_] == input.user
+}`,
+			expectResult: []*ast.Location{
+				{Row: 1, Col: 2, Offset: 1, Text: []byte(`"admin"`), File: "roles/data.json"},
+			},
+		},
+		"Should return the key in the YAML document through the import": {
+			src: `package main
+
+import data.config
+
+allow {
+	input.cpu < config.limits.c|pu
+}`,
+			expectResult: []*ast.Location{
+				{Row: 2, Col: 3, Offset: len("limits:\n  "), Text: []byte("cpu"), File: "config/data.yaml"},
+			},
+		},
+		"Should return the quoted key in the YAML document": {
+			src: `package main
+
+allow {
+	data.config.limits.mem|ory
+}`,
+			expectResult: []*ast.Location{
+				{Row: 3, Col: 3, Offset: len("limits:\n  cpu: 2\n  "), Text: []byte(`"memory"`), File: "config/data.yaml"},
+			},
+		},
+		"Should return the key after the non-ASCII text in the YAML document": {
+			src: `package main
+
+allow {
+	data.i18n.messages.fare|well
+}`,
+			expectResult: []*ast.Location{
+				{Row: 1, Col: len([]rune(`messages: {"挨拶": hello, `)) + 1, Offset: len(`messages: {"挨拶": hello, `), Text: []byte(`"farewell"`), File: "i18n/data.yaml"},
+			},
+		},
+		"Should return the head of the document": {
+			src: `package main
+
+allow {
+	data.ro|les
+}`,
+			expectResult: []*ast.Location{
+				{Row: 1, Col: 1, Offset: 0, Text: []byte("{"), File: "roles/data.json"},
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files := map[string]source.File{"src.rego": {RawText: tt.src}}
+			for path, f := range dataTestFiles {
+				files[path] = f
+			}
+			project, location, dir := newProjectOnDisk(t, files)
+
+			got, err := project.LookupDefinition(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, l := range tt.expectResult {
+				l.File = filepath.Join(dir, l.File)
+			}
+			if diff := cmp.Diff(tt.expectResult, got); diff != "" {
+				t.Errorf("LookupDefinition result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProject_TermDocument_Data(t *testing.T) {
+	files := map[string]source.File{
+		"src.rego": {RawText: `package main
+
+allow {
+	data.roles.adm|in[_] == input.user
+}`},
+	}
+	for path, f := range dataTestFiles {
+		files[path] = f
+	}
+	project, location, _ := newProjectOnDisk(t, files)
+
+	got, err := project.TermDocument(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []source.Document{
+		{Content: "[\n  \"alice\"\n]", Language: "json"},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("TermDocument result diff (-expect, +got)\n%s", diff)
+	}
+}
+
+func TestProject_ListCompletionItems_Data(t *testing.T) {
+	tests := map[string]struct {
+		src         string
+		expectItems []source.CompletionItem
+	}{
+		"Should list the keys in the document": {
+			src: `package main
+
+allow {
+	data.roles.v|
+}`,
+			expectItems: []source.CompletionItem{
+				{
+					Label:  "viewer",
+					Kind:   source.FieldItem,
+					Detail: "[]",
+					TextEdit: &source.TextEdit{
+						Row:  4,
+						Col:  13,
+						Text: "viewer",
+					},
+				},
+			},
+		},
+		"Should list the directories of the documents": {
+			src: `package main
+
+allow {
+	data.c|
+}`,
+			expectItems: []source.CompletionItem{
+				{
+					Label: "config",
+					Kind:  source.FieldItem,
+					TextEdit: &source.TextEdit{
+						Row:  4,
+						Col:  7,
+						Text: "config",
+					},
+				},
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files := map[string]source.File{"src.rego": {RawText: tt.src}}
+			for path, f := range dataTestFiles {
+				files[path] = f
+			}
+			project, location, _ := newProjectOnDisk(t, files)
+
+			got, err := project.ListCompletionItems(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expectItems, got); diff != "" {
+				t.Errorf("ListCompletionItems result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
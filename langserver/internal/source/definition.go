@@ -60,6 +60,24 @@ func (p *Project) findDefinitionInRule(term *ast.Term, rule *ast.Rule) *ast.Term
 		return nil
 	}
 
+	// The variables in the comprehension and every are defined in its scope.
+	// The head of the comprehension is written before the definition.
+	// [x | x := input[_]]
+	for _, s := range findScopes(term.Loc(), rule) {
+		result := p.findDefinitionInTerms(term, s.vars)
+		if result != nil {
+			return result
+		}
+
+		loc := *term.Location
+		loc.Offset = s.location.Offset + len(s.location.Text)
+		target := &ast.Term{Value: term.Value, Location: &loc}
+		result = p.findDefinitionInBody(target, s.body)
+		if result != nil {
+			return result
+		}
+	}
+
 	// violation[msg]
 	//           ^ this is key
 	if rule.Head.Key != nil {
@@ -85,7 +103,18 @@ func (p *Project) findDefinitionInRule(term *ast.Term, rule *ast.Rule) *ast.Term
 		return result
 	}
 
-	for _, b := range rule.Body {
+	// a.b[x] := 1
+	//      ^ this is the var of the ref head
+	result = p.findDefinitionInTerms(term, rule.Head.Ref()[1:])
+	if result != nil {
+		return result
+	}
+
+	return p.findDefinitionInBody(term, rule.Body)
+}
+
+func (p *Project) findDefinitionInBody(term *ast.Term, body ast.Body) *ast.Term {
+	for _, b := range body {
 		switch t := b.Terms.(type) {
 		case *ast.Term:
 			result := p.findDefinitionInTerm(term, t, true)
@@ -99,6 +128,24 @@ func (p *Project) findDefinitionInRule(term *ast.Term, rule *ast.Rule) *ast.Term
 					return result
 				}
 			}
+			// 1 = x
+			//     ^ the right side of the unification also binds the variable unless it is a rule or an import.
+			if b.IsEquality() {
+				if result := p.findDefinitionInTerm(term, t[2], false); result != nil && len(p.findDefinitionOutOfRule(term)) == 0 {
+					return result
+				}
+			}
+		case *ast.SomeDecl:
+			// some x
+			// some k, v in xs
+			for _, symbol := range t.Symbols {
+				result := p.findDefinitionInTerms(term, someDeclVars(symbol))
+				if result != nil {
+					return result
+				}
+			}
+		case *ast.Every:
+			// The variables in every are defined only in its scope.
 		default:
 			fmt.Fprintf(os.Stderr, "type: %T(%+v)", b.Terms, b.Terms)
 		}
@@ -150,6 +197,15 @@ func (p *Project) findDefinitionInTerm(target *ast.Term, term *ast.Term, isState
 			return t
 		}
 		return nil
+	case ast.Object:
+		// {"a": a} := input
+		for _, key := range v.Keys() {
+			t := p.findDefinitionInTerm(target, v.Get(key), false)
+			if t != nil {
+				return t
+			}
+		}
+		return nil
 	case ast.Var:
 		if target.Equal(term) && target.Loc().Offset >= term.Loc().Offset {
 			return term
@@ -178,15 +234,8 @@ func (p *Project) findDefinitionInModule(term *ast.Term) []*ast.Location {
 	result := make([]*ast.Location, 0)
 	for _, mod := range searchPolicies {
 		for _, rule := range mod.Rules {
-			if rule.Head.Name.String() == word {
-				loc := &ast.Location{
-					Row:    rule.Location.Row,
-					Col:    rule.Location.Col,
-					File:   rule.Location.File,
-					Text:   []byte(rule.Head.Name.String()),
-					Offset: rule.Location.Offset,
-				}
-				result = append(result, loc)
+			if ruleName(rule).String() == word {
+				result = append(result, ruleNameLocation(rule))
 			}
 		}
 	}
@@ -258,8 +307,17 @@ func (p *Project) findDefinitionOutOfRule(term *ast.Term) []*ast.Location {
 	if len(locations) != 0 {
 		return locations
 	}
-	if isImportTerm(term) {
-		return p.findImportDefinitions(term)
+	if ref := p.dataRef(term); ref != nil {
+		// data.roles.admin may refer to both the rule and the data document.
+		locations = append(p.findImportDefinitions(&ast.Term{Value: ref, Location: term.Location}), p.findDataDefinitions(ref)...)
+		if len(locations) != 0 || isImportTerm(term) {
+			return locations
+		}
+	}
+	if ref := p.localRuleRef(term); ref != nil {
+		if locations := p.findRuleDefinitions(ref); len(locations) != 0 {
+			return locations
+		}
 	}
 	return p.findDefinitionInModule(term)
 }
@@ -322,9 +380,119 @@ func (p *Project) findImportDefinitions(term *ast.Term) []*ast.Location {
 	}
 
 	modules := p.cache.FindPolicies(val)
+	if len(modules) == 0 && len(val) > 2 {
+		// data.lib.rule -> find rule in data.lib
+		// data.lib.servers.web.port -> find servers.web.port in data.lib
+		return p.findRuleDefinitions(val)
+	}
 	result := make([]*ast.Location, len(modules))
 	for i, m := range modules {
 		result[i] = m.Package.Loc()
 	}
 	return result
 }
+
+func (p *Project) findRuleDefinitions(ref ast.Ref) []*ast.Location {
+	rules := p.findRulesByRef(ref)
+	result := make([]*ast.Location, len(rules))
+	for i, rule := range rules {
+		result[i] = ruleNameLocation(rule)
+	}
+	return result
+}
+
+// findRulesByRef returns the rules which the data ref refers to.
+// The rest of the ref after the longest package is matched against the head ref of the rules.
+//
+//	data.lib.servers.web.port -> servers.web.port := 80 in package lib
+func (p *Project) findRulesByRef(ref ast.Ref) []*ast.Rule {
+	for i := len(ref) - 1; i > 0; i-- {
+		result := make([]*ast.Rule, 0)
+		for _, mod := range p.cache.FindPolicies(ref[:i]) {
+			for _, rule := range mod.Rules {
+				if matchRuleRef(rule.Head.Ref(), ref[i:]) {
+					result = append(result, rule)
+				}
+			}
+		}
+		if len(result) != 0 {
+			return result
+		}
+	}
+	return nil
+}
+
+// matchRuleRef reports whether the path in the package refers to the rule of the head ref.
+// The var matches any key, and the shorter one is matched as the prefix of the other.
+//
+//	servers.web.port matches servers, servers.web.port and servers.web.port.tcp
+//	ports[name]      matches ports.web
+func matchRuleRef(head ast.Ref, path ast.Ref) bool {
+	if len(head) == 0 || len(path) == 0 {
+		return false
+	}
+	name, ok := head[0].Value.(ast.Var)
+	if !ok || !ast.String(name).Equal(path[0].Value) {
+		return false
+	}
+
+	for i := 1; i < min(len(head), len(path)); i++ {
+		if _, ok := head[i].Value.(ast.Var); ok {
+			continue
+		}
+		if _, ok := path[i].Value.(ast.Var); ok {
+			continue
+		}
+		if !head[i].Equal(path[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// localRuleRef returns the data ref of the ref which refers to the rule in the same package.
+// It returns nil when the head of the ref is the root document or the import.
+//
+//	servers.web.port -> data.main.servers.web.port
+func (p *Project) localRuleRef(term *ast.Term) ast.Ref {
+	ref, ok := term.Value.(ast.Ref)
+	if !ok || len(ref) < 2 {
+		return nil
+	}
+	head, ok := ref[0].Value.(ast.Var)
+	if !ok || ast.RootDocumentNames.Contains(ref[0]) {
+		return nil
+	}
+
+	module := p.GetModule(term.Loc().File)
+	if module == nil || findImportOutsidePolicy(string(head), module.Imports) != nil {
+		return nil
+	}
+	return module.Package.Path.Append(ast.StringTerm(string(head))).Concat(ref[1:])
+}
+
+// ruleName returns the first var of the rule head ref.
+// The rules which have the same name are merged into the same document.
+//
+//	deny contains msg if { ... } -> deny
+//	a.b.c := 1                   -> a
+//	a.b[x] := 1 if { ... }       -> a
+func ruleName(rule *ast.Rule) ast.Var {
+	ref := rule.Head.Ref()
+	if len(ref) == 0 {
+		return rule.Head.Name
+	}
+	name, _ := ref[0].Value.(ast.Var)
+	return name
+}
+
+// ruleNameLocation returns the location of the rule name.
+func ruleNameLocation(rule *ast.Rule) *ast.Location {
+	return &ast.Location{
+		Row:    rule.Head.Location.Row,
+		Col:    rule.Head.Location.Col,
+		File:   rule.Head.Location.File,
+		Text:   []byte(ruleName(rule)),
+		Offset: rule.Head.Location.Offset,
+	}
+}
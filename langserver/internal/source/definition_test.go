@@ -16,6 +16,8 @@ func TestLookupDefinition(t *testing.T) {
 		files        map[string]source.File
 		expectResult []*ast.Location
 		expectErr    error
+		// location is used when the cursor can't be written with "|". e.g. comprehension
+		location createLocationFunc
 	}{
 		"Should return variable definition in the rule": {
 			files: map[string]source.File{
@@ -80,6 +82,29 @@ test(msg) = test {
 				},
 			},
 		},
+		"Should return rule definition on the right side of the unification": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+limit = 3
+
+allow {
+	x = lim|it
+	count(input.users) < x
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    3,
+					Col:    1,
+					Offset: len("package main\n\n"),
+					Text:   []byte("limit"),
+					File:   "src.rego",
+				},
+			},
+		},
 		"Should return definition in the other file but same package": {
 			files: map[string]source.File{
 				"src.rego": {
@@ -280,11 +305,256 @@ import data.lib|`,
 				},
 			},
 		},
+		"Should return definition in the comprehension": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+violation[msg] {
+	msg := [x| | x := input.a[_]]
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    4,
+					Col:    14,
+					Offset: len("package main\n\nviolation[msg] {\n	msg := [x | "),
+					Text:   []byte("x"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should return definition out of the comprehension": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+violation[msg] {
+	y := 1
+	msg := {"a": [x | x := input.a[_]; x > y]}
+}`,
+				},
+			},
+			location: createLocation(5, 41, "src.rego"),
+			expectResult: []*ast.Location{
+				{
+					Row:    4,
+					Col:    2,
+					Offset: len("package main\n\nviolation[msg] {\n	"),
+					Text:   []byte("y"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should return definition of some in": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import future.keywords.in
+
+violation[msg] {
+	some k, v in input.a
+	msg := v|
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    6,
+					Col:    10,
+					Offset: len("package main\n\nimport future.keywords.in\n\nviolation[msg] {\n	some k, "),
+					Text:   []byte("v"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should return definition in every": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import future.keywords.every
+
+allow {
+	every x in input.a {
+		x| > 1
+	}
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    6,
+					Col:    8,
+					Offset: len("package main\n\nimport future.keywords.every\n\nallow {\n	every "),
+					Text:   []byte("x"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should return definition of the multi-value rule": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import rego.v1
+
+deny contains msg if {
+	msg := "hello"
+}
+
+test_deny if {
+	count(d|eny) == 1
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    5,
+					Col:    1,
+					Offset: len("package main\n\nimport rego.v1\n\n"),
+					Text:   []byte("deny"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should return definition of the ref head rule": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import rego.v1
+
+allow if {
+	s|ervers.web.port == 80
+}
+
+servers.web.port := 80`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    9,
+					Col:    1,
+					Offset: len("package main\n\nimport rego.v1\n\nallow if {\n	servers.web.port == 80\n}\n\n"),
+					Text:   []byte("servers"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should return definition of the ref head rule in other package": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import data.lib
+
+allow {
+	lib.servers.web.po|rt == 80
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+servers.db.port := 5432
+
+servers.web.port := 80`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    5,
+					Col:    1,
+					Offset: len("package lib\n\nservers.db.port := 5432\n\n"),
+					Text:   []byte("servers"),
+					File:   "lib.rego",
+				},
+			},
+		},
+		"Should return definition of the ref head rule by the full path": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+allow {
+	data.lib.servers.web.po|rt == 80
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+servers.db.port := 5432
+
+servers.web.port := 80`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    5,
+					Col:    1,
+					Offset: len("package lib\n\nservers.db.port := 5432\n\n"),
+					Text:   []byte("servers"),
+					File:   "lib.rego",
+				},
+			},
+		},
+		"Should return definition of the ref head rule by the rest of the path": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+allow {
+	servers.web.po|rt == 80
+}
+
+servers.db.port := 5432
+
+servers.web.port := 80`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    9,
+					Col:    1,
+					Offset: len("package main\n\nallow {\n	servers.web.port == 80\n}\n\nservers.db.port := 5432\n\n"),
+					Text:   []byte("servers"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should return definition of the var in the ref head": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import rego.v1
+
+ports[name] := port if {
+	some name, port in input.servers
+	n|ame != ""
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    5,
+					Col:    7,
+					Offset: len("package main\n\nimport rego.v1\n\nports["),
+					Text:   []byte("name"),
+					File:   "src.rego",
+				},
+			},
+		},
 	}
 
 	for n, tt := range tests {
 		t.Run(n, func(t *testing.T) {
 			files, location, err := helper.GetAstLocation(tt.files)
+			if tt.location != nil {
+				files, location, err = tt.files, tt.location(tt.files), nil
+			}
 			if err != nil {
 				t.Fatalf("failed to GetLspPosition: %v", err)
 			}
@@ -6,12 +6,6 @@ import (
 	"github.com/open-policy-agent/opa/ast"
 )
 
-const (
-	BuiltinDetail = `built-in function
-
-See https://www.openpolicyagent.org/docs/latest/policy-reference/#built-in-functions`
-)
-
 type Document struct {
 	Content  string
 	Language string
@@ -44,17 +38,52 @@ func (p *Project) findTermDocument(term *ast.Term) []Document {
 			if b.Name == term.String() {
 				return []Document{
 					{
-						Content:  b.Name + b.Decl.FuncArgs().String(),
+						Content:  builtinSignature(b),
 						Language: "rego",
 					},
 					{
-						Content:  BuiltinDetail,
+						Content:  builtinDocumentation(b),
 						Language: "markdown",
 					},
 				}
 			}
 		}
 	}
+
+	if docs := p.findInputDocuments(term); len(docs) != 0 {
+		return docs
+	}
+
+	if docs := p.findSampleInputDocuments(term); len(docs) != 0 {
+		return docs
+	}
+
+	if ref := p.dataRef(term); ref != nil {
+		if docs := p.findDataDocuments(ref); len(docs) != 0 {
+			return docs
+		}
+		if docs := p.findPackageDocuments(ref); len(docs) != 0 {
+			return docs
+		}
+		if docs := ruleDocuments(p.findRulesByRef(ref)); len(docs) != 0 {
+			return docs
+		}
+	}
+
+	// servers.web.port in the same package
+	if ref := p.localRuleRef(term); ref != nil {
+		if docs := ruleDocuments(p.findRulesByRef(ref)); len(docs) != 0 {
+			return docs
+		}
+	}
+
+	// import data.lib
+	// l|ib.rule
+	if _, ok := term.Value.(ast.Var); ok {
+		if ref := p.dataRef(&ast.Term{Value: ast.Ref{term}, Location: term.Location}); ref != nil {
+			return p.findPackageDocuments(ref)
+		}
+	}
 	return p.findTermDocumentInModule(term)
 }
 
@@ -70,17 +99,51 @@ func (p *Project) findTermDocumentInModule(term *ast.Term) []Document {
 		word = word[strings.Index(word, ".")+1:]
 	}
 
-	result := make([]Document, 0)
+	rules := make([]*ast.Rule, 0)
 	for _, mod := range searchPolicies {
 		for _, rule := range mod.Rules {
-			if rule.Head.Name.String() == word {
-				result = append(result, Document{
-					Content:  createDocForRule(rule),
-					Language: "rego",
-				})
+			if ruleName(rule).String() == word {
+				rules = append(rules, rule)
 			}
 		}
 	}
+	return ruleDocuments(rules)
+}
+
+// ruleDocuments returns the source and the annotations or the comments of the rules.
+func ruleDocuments(rules []*ast.Rule) []Document {
+	result := make([]Document, 0)
+	for _, rule := range rules {
+		result = append(result, Document{
+			Content:  createDocForRule(rule),
+			Language: "rego",
+		})
+		if doc := ruleDocumentation(rule); doc != "" {
+			result = append(result, Document{
+				Content:  doc,
+				Language: "markdown",
+			})
+		}
+	}
+	return result
+}
+
+// findPackageDocuments returns the package and its annotations or comments for each module.
+func (p *Project) findPackageDocuments(ref ast.Ref) []Document {
+	result := make([]Document, 0)
+	for _, m := range p.cache.FindPolicies(ref) {
+		doc := packageDocumentation(m)
+		if doc == "" {
+			continue
+		}
+		result = append(result, Document{
+			Content:  m.Package.String(),
+			Language: "rego",
+		}, Document{
+			Content:  doc,
+			Language: "markdown",
+		})
+	}
 	return result
 }
 
@@ -0,0 +1,159 @@
+package source
+
+import (
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+type DocumentHighlight struct {
+	Location *ast.Location
+	Kind     DocumentHighlightKind
+}
+
+type DocumentHighlightKind int
+
+const (
+	ReadHighlight DocumentHighlightKind = iota
+	WriteHighlight
+)
+
+// DocumentHighlights lists the occurrences of the term at the location in the same file.
+// The definitions and the assignments are WriteHighlight.
+func (p *Project) DocumentHighlights(location *ast.Location) ([]DocumentHighlight, error) {
+	term, err := p.SearchTargetTerm(location)
+	if err != nil {
+		return nil, err
+	}
+	if term == nil {
+		return nil, nil
+	}
+
+	module := p.GetModule(location.File)
+	if module == nil {
+		return nil, nil
+	}
+
+	references, err := p.LookupReferences(location)
+	if err != nil {
+		return nil, err
+	}
+
+	writeOffsets := listWriteOffsets(module)
+	refVarOffsets := listRefVarOffsets(module)
+	for _, d := range p.findDefinition(term) {
+		// The var in the ref is the first occurrence, but it is not bound by the assignment. e.g. data.x[i] = y
+		if _, ok := refVarOffsets[d.Offset]; ok {
+			continue
+		}
+		if d.File == location.File {
+			writeOffsets[d.Offset] = struct{}{}
+		}
+	}
+
+	result := make([]DocumentHighlight, 0, len(references))
+	for _, r := range references {
+		if r.File != location.File {
+			continue
+		}
+		kind := ReadHighlight
+		if _, ok := writeOffsets[r.Offset]; ok {
+			kind = WriteHighlight
+		}
+		result = append(result, DocumentHighlight{Location: r, Kind: kind})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Location.Offset < result[j].Location.Offset
+	})
+	return result, nil
+}
+
+// listWriteOffsets lists the offsets where the values are bound.
+//
+//	rule { ... }
+//	f(arg) { ... }
+//	x := 1
+//	[x, y] = [1, 2]
+//	1 = x
+//	some x
+//	every x in xs { ... }
+func listWriteOffsets(module *ast.Module) map[int]struct{} {
+	result := make(map[int]struct{})
+	addVars := func(x any) {
+		ast.WalkTerms(x, func(t *ast.Term) bool {
+			if _, ok := t.Value.(ast.Var); ok && t.Location != nil {
+				result[t.Location.Offset] = struct{}{}
+			}
+			return false
+		})
+	}
+
+	// addBindings adds the vars which are bound by the unification.
+	// The vars in the refs and the root documents are not bound. e.g. input.user = x, data.x[i] = y
+	var addBindings func(t *ast.Term)
+	addBindings = func(t *ast.Term) {
+		switch v := t.Value.(type) {
+		case ast.Var:
+			if t.Location != nil && !ast.RootDocumentNames.Contains(t) {
+				result[t.Location.Offset] = struct{}{}
+			}
+		case *ast.Array:
+			v.Foreach(addBindings)
+		case ast.Object:
+			v.Foreach(func(_, value *ast.Term) {
+				addBindings(value)
+			})
+		}
+	}
+
+	for _, rule := range module.Rules {
+		result[ruleNameLocation(rule).Offset] = struct{}{}
+		for r := rule; r != nil; r = r.Else {
+			addVars(r.Head.Args)
+		}
+
+		ast.WalkExprs(rule, func(e *ast.Expr) bool {
+			switch t := e.Terms.(type) {
+			case []*ast.Term:
+				switch {
+				case e.IsAssignment():
+					addBindings(t[1])
+				case e.IsEquality():
+					// Both sides of "=" can bind the vars. e.g. 1 = x
+					addBindings(t[1])
+					addBindings(t[2])
+				}
+			case *ast.SomeDecl:
+				for _, s := range t.Symbols {
+					addVars(ast.Args(someDeclVars(s)))
+				}
+			case *ast.Every:
+				addVars(ast.Args(everyVars(t)))
+			}
+			return false
+		})
+	}
+	return result
+}
+
+// listRefVarOffsets lists the offsets of the vars in the refs of the rule bodies.
+//
+//	data.x[i]
+//	       ^
+func listRefVarOffsets(module *ast.Module) map[int]struct{} {
+	result := make(map[int]struct{})
+	for _, rule := range module.Rules {
+		for r := rule; r != nil; r = r.Else {
+			ast.WalkRefs(r.Body, func(ref ast.Ref) bool {
+				for _, t := range ref[1:] {
+					if _, ok := t.Value.(ast.Var); ok && t.Location != nil {
+						result[t.Location.Offset] = struct{}{}
+					}
+				}
+				return false
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,221 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/kitagry/regols/langserver/internal/source/helper"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+func TestProject_DocumentHighlights(t *testing.T) {
+	tests := map[string]struct {
+		files        map[string]source.File
+		expectResult []source.DocumentHighlight
+	}{
+		"Should highlight local variable": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	m := "hello"
+	msg := m|
+}`,
+				},
+			},
+			expectResult: []source.DocumentHighlight{
+				{
+					Location: &ast.Location{
+						Row:    4,
+						Col:    2,
+						Offset: len("package src\n\nviolation[msg] {\n	"),
+						Text:   []byte("m"),
+						File:   "src.rego",
+					},
+					Kind: source.WriteHighlight,
+				},
+				{
+					Location: &ast.Location{
+						Row:    5,
+						Col:    9,
+						Offset: len("package src\n\nviolation[msg] {\n	m := \"hello\"\n	msg := "),
+						Text:   []byte("m"),
+						File:   "src.rego",
+					},
+					Kind: source.ReadHighlight,
+				},
+			},
+		},
+		"Should highlight variable which is bound on the right side of unification": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	1 = x
+	msg := x|
+}`,
+				},
+			},
+			expectResult: []source.DocumentHighlight{
+				{
+					Location: &ast.Location{
+						Row:    4,
+						Col:    6,
+						Offset: len("package src\n\nviolation[msg] {\n	1 = "),
+						Text:   []byte("x"),
+						File:   "src.rego",
+					},
+					Kind: source.WriteHighlight,
+				},
+				{
+					Location: &ast.Location{
+						Row:    5,
+						Col:    9,
+						Offset: len("package src\n\nviolation[msg] {\n	1 = x\n	msg := "),
+						Text:   []byte("x"),
+						File:   "src.rego",
+					},
+					Kind: source.ReadHighlight,
+				},
+			},
+		},
+		"Should not highlight ref head in unification as write": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	input.user = x
+	msg := inp|ut.name
+}`,
+				},
+			},
+			expectResult: []source.DocumentHighlight{
+				{
+					Location: &ast.Location{
+						Row:    4,
+						Col:    2,
+						Offset: len("package src\n\nviolation[msg] {\n	"),
+						Text:   []byte("input"),
+						File:   "src.rego",
+					},
+					Kind: source.ReadHighlight,
+				},
+				{
+					Location: &ast.Location{
+						Row:    5,
+						Col:    9,
+						Offset: len("package src\n\nviolation[msg] {\n	input.user = x\n	msg := "),
+						Text:   []byte("input"),
+						File:   "src.rego",
+					},
+					Kind: source.ReadHighlight,
+				},
+			},
+		},
+		"Should not highlight var in ref in unification as write": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	data.x[i] = msg
+	msg == i|
+}`,
+				},
+			},
+			expectResult: []source.DocumentHighlight{
+				{
+					Location: &ast.Location{
+						Row:    4,
+						Col:    9,
+						Offset: len("package src\n\nviolation[msg] {\n	data.x["),
+						Text:   []byte("i"),
+						File:   "src.rego",
+					},
+					Kind: source.ReadHighlight,
+				},
+				{
+					Location: &ast.Location{
+						Row:    5,
+						Col:    9,
+						Offset: len("package src\n\nviolation[msg] {\n	data.x[i] = msg\n	msg == "),
+						Text:   []byte("i"),
+						File:   "src.rego",
+					},
+					Kind: source.ReadHighlight,
+				},
+			},
+		},
+		"Should highlight rule only in the same file": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+is_hello(msg) {
+	msg == "hello"
+}
+
+violation[msg] {
+	is_h|ello(msg)
+}`,
+				},
+				"other.rego": {
+					RawText: `package src
+
+allow {
+	is_hello("hello")
+}`,
+				},
+			},
+			expectResult: []source.DocumentHighlight{
+				{
+					Location: &ast.Location{
+						Row:    3,
+						Col:    1,
+						Offset: len("package src\n\n"),
+						Text:   []byte("is_hello"),
+						File:   "src.rego",
+					},
+					Kind: source.WriteHighlight,
+				},
+				{
+					Location: &ast.Location{
+						Row:    8,
+						Col:    2,
+						Offset: len("package src\n\nis_hello(msg) {\n	msg == \"hello\"\n}\n\nviolation[msg] {\n	"),
+						Text:   []byte("is_hello"),
+						File:   "src.rego",
+					},
+					Kind: source.ReadHighlight,
+				},
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files, location, err := helper.GetAstLocation(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := source.NewProjectWithFiles(files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := p.DocumentHighlights(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expectResult, got); diff != "" {
+				t.Errorf("DocumentHighlights result diff (-expect +got):\n%s", diff)
+			}
+		})
+	}
+}
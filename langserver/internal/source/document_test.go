@@ -67,13 +67,18 @@ violation[msg] {
 			},
 			expectDocs: []source.Document{
 				{
-					Content:  "sprintf(string, array[any])",
+					Content:  "sprintf(format: string, values: array[any]) => output: string",
 					Language: "rego",
 				},
 				{
-					Content: `built-in function
-
-See https://www.openpolicyagent.org/docs/latest/policy-reference/#built-in-functions`,
+					Content: "Returns the given string, formatted.\n\n" +
+						"**Arguments**\n\n" +
+						"- `format: string`: string with formatting verbs\n" +
+						"- `values: array[any]`: arguments to format into formatting verbs\n\n" +
+						"**Returns**\n\n" +
+						"- `output: string`: `format` formatted by the values in `values`\n\n" +
+						"*Categories: strings*\n\n" +
+						"[Policy reference](https://www.openpolicyagent.org/docs/latest/policy-reference/#builtin-strings-sprintf)",
 					Language: "markdown",
 				},
 			},
@@ -90,13 +95,208 @@ violation[msg] {
 			},
 			expectDocs: []source.Document{
 				{
-					Content:  "json.is_valid(string)",
+					Content:  "json.is_valid(x: string) => result: boolean",
+					Language: "rego",
+				},
+				{
+					Content: "Verifies the input string is a valid JSON document.\n\n" +
+						"**Arguments**\n\n" +
+						"- `x: string`: a JSON string\n\n" +
+						"**Returns**\n\n" +
+						"- `result: boolean`: `true` if `x` is valid JSON, `false` otherwise\n\n" +
+						"*Categories: encoding*\n\n" +
+						"[Policy reference](https://www.openpolicyagent.org/docs/latest/policy-reference/#builtin-encoding-jsonis_valid)",
+					Language: "markdown",
+				},
+			},
+		},
+		"Should document ref head rule": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import rego.v1
+
+allow if {
+	s|ervers.web.port == 80
+}
+
+servers.web.port := 80`,
+				},
+			},
+			expectDocs: []source.Document{
+				{
+					Content:  `servers.web.port := 80`,
+					Language: "rego",
+				},
+			},
+		},
+		"Should document ref head rule by the rest of the path": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+allow {
+	servers.web.po|rt == 80
+}
+
+servers.db.port := 5432
+
+servers.web.port := 80`,
+				},
+			},
+			expectDocs: []source.Document{
+				{
+					Content:  `servers.web.port := 80`,
+					Language: "rego",
+				},
+			},
+		},
+		"Should document ref head rule in other package": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import data.lib
+
+allow {
+	lib.servers.web.po|rt == 80
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+servers.db.port := 5432
+
+servers.web.port := 80`,
+				},
+			},
+			expectDocs: []source.Document{
+				{
+					Content:  `servers.web.port := 80`,
+					Language: "rego",
+				},
+			},
+		},
+		"Should document ref head rule by the full path": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+allow {
+	data.lib.servers.web.po|rt == 80
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+servers.db.port := 5432
+
+servers.web.port := 80`,
+				},
+			},
+			expectDocs: []source.Document{
+				{
+					Content:  `servers.web.port := 80`,
+					Language: "rego",
+				},
+			},
+		},
+		"Should document rule with METADATA annotations": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	is_|admin
+}
+
+# METADATA
+# title: Admin check
+# description: Allow the admin users.
+# related_resources:
+#   - ref: https://example.com/admin
+#     description: Admin guide
+#   - https://example.com/users
+# custom:
+#   severity: high
+is_admin {
+	input.user == "admin"
+}`,
+				},
+			},
+			expectDocs: []source.Document{
+				{
+					Content: `is_admin {
+	input.user == "admin"
+}`,
 					Language: "rego",
 				},
 				{
-					Content: `built-in function
+					Content: "### Admin check\n\nAllow the admin users.\n\n" +
+						"**Related resources**\n\n- [Admin guide](https://example.com/admin)\n- <https://example.com/users>\n\n" +
+						"**Custom**\n\n- severity: `\"high\"`\n\n*Scope: rule*",
+					Language: "markdown",
+				},
+			},
+		},
+		"Should document rule with comments": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	is_|admin
+}
 
-See https://www.openpolicyagent.org/docs/latest/policy-reference/#built-in-functions`,
+# is_admin returns true
+# when the user is admin.
+is_admin {
+	input.user == "admin" # trailing comment
+}`,
+				},
+			},
+			expectDocs: []source.Document{
+				{
+					Content: `is_admin {
+	input.user == "admin" # trailing comment
+}`,
+					Language: "rego",
+				},
+				{
+					Content:  "is_admin returns true\nwhen the user is admin.",
+					Language: "markdown",
+				},
+			},
+		},
+		"Should document package with comments": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib
+
+violation[msg] {
+	l|ib.is_admin
+}`,
+				},
+				"lib.rego": {
+					RawText: `# METADATA
+# description: Library of the user checks.
+package lib
+
+is_admin {
+	input.user == "admin"
+}`,
+				},
+			},
+			expectDocs: []source.Document{
+				{
+					Content:  "package lib",
+					Language: "rego",
+				},
+				{
+					Content:  "Library of the user checks.\n\n*Scope: package*",
 					Language: "markdown",
 				},
 			},
@@ -0,0 +1,107 @@
+package source
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+type FoldingRange struct {
+	// StartRow and EndRow are 1-based and inclusive.
+	StartRow int
+	EndRow   int
+	Kind     FoldingRangeKind
+}
+
+type FoldingRangeKind int
+
+const (
+	RegionFolding FoldingRangeKind = iota
+	CommentFolding
+	ImportsFolding
+)
+
+// ListFoldingRanges lists the ranges of the rules, the else rules, the multi-line literals,
+// the comprehensions, every blocks, the consecutive comments and the imports.
+func (p *Project) ListFoldingRanges(path string) []FoldingRange {
+	module := p.GetModule(path)
+	if module == nil {
+		return nil
+	}
+
+	result := make([]FoldingRange, 0)
+	add := func(startRow, endRow int, kind FoldingRangeKind) {
+		if startRow < endRow {
+			result = append(result, FoldingRange{StartRow: startRow, EndRow: endRow, Kind: kind})
+		}
+	}
+
+	if len(module.Imports) > 0 {
+		add(module.Imports[0].Location.Row, module.Imports[len(module.Imports)-1].Location.Row, ImportsFolding)
+	}
+
+	for i := 0; i < len(module.Comments); {
+		j := i
+		for j+1 < len(module.Comments) && module.Comments[j+1].Location.Row == module.Comments[j].Location.Row+1 {
+			j++
+		}
+		add(module.Comments[i].Location.Row, module.Comments[j].Location.Row, CommentFolding)
+		i = j + 1
+	}
+
+	for _, rule := range module.Rules {
+		for r := rule; r != nil; r = r.Else {
+			// The location of the rule contains the else rules.
+			//
+			//	f(x) = 1 {
+			//	  x > 1
+			//	} else = 2 {  <- the fold of f ends before this line.
+			//	  x > 2
+			//	}
+			if r.Else != nil {
+				add(r.Location.Row, r.Else.Location.Row-1, RegionFolding)
+			} else {
+				add(r.Location.Row, lastRow(r.Location)-1, RegionFolding)
+			}
+		}
+
+		ast.WalkTerms(rule, func(t *ast.Term) bool {
+			switch t.Value.(type) {
+			case ast.Object, *ast.Array, ast.Set, *ast.ArrayComprehension, *ast.SetComprehension, *ast.ObjectComprehension:
+				if t.Location != nil {
+					add(t.Location.Row, lastRow(t.Location)-1, RegionFolding)
+				}
+			}
+			return false
+		})
+		ast.WalkExprs(rule, func(e *ast.Expr) bool {
+			if _, ok := e.Terms.(*ast.Every); ok && e.Location != nil {
+				add(e.Location.Row, lastRow(e.Location)-1, RegionFolding)
+			}
+			return false
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].StartRow != result[j].StartRow {
+			return result[i].StartRow < result[j].StartRow
+		}
+		return result[i].EndRow < result[j].EndRow
+	})
+
+	// `x := {` makes the same ranges of the rule and the object.
+	uniq := make([]FoldingRange, 0, len(result))
+	for _, r := range result {
+		if len(uniq) > 0 && uniq[len(uniq)-1] == r {
+			continue
+		}
+		uniq = append(uniq, r)
+	}
+	return uniq
+}
+
+// lastRow returns the row of the end of the location.
+func lastRow(loc *ast.Location) int {
+	return loc.Row + strings.Count(string(loc.Text), "\n")
+}
@@ -0,0 +1,86 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+)
+
+func TestProject_ListFoldingRanges(t *testing.T) {
+	tests := map[string]struct {
+		files        map[string]source.File
+		path         string
+		expectResult []source.FoldingRange
+	}{
+		"Should fold imports, comments, rules and else": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib
+import data.util
+
+# METADATA
+# title: f
+f(x) = 1 {
+	x > 1
+} else = 2 {
+	x > 2
+}`,
+				},
+			},
+			path: "src.rego",
+			expectResult: []source.FoldingRange{
+				{StartRow: 3, EndRow: 4, Kind: source.ImportsFolding},
+				{StartRow: 6, EndRow: 7, Kind: source.CommentFolding},
+				{StartRow: 8, EndRow: 9, Kind: source.RegionFolding},
+				{StartRow: 10, EndRow: 11, Kind: source.RegionFolding},
+			},
+		},
+		"Should fold literals, comprehensions and every": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import future.keywords.every
+
+table := {
+	"a": 1,
+	"b": 2,
+}
+
+allow {
+	xs := [x |
+		x := table[_]
+	]
+	every x in xs {
+		x > 0
+	}
+}`,
+				},
+			},
+			path: "src.rego",
+			expectResult: []source.FoldingRange{
+				{StartRow: 5, EndRow: 7, Kind: source.RegionFolding},
+				{StartRow: 10, EndRow: 16, Kind: source.RegionFolding},
+				{StartRow: 11, EndRow: 12, Kind: source.RegionFolding},
+				{StartRow: 14, EndRow: 15, Kind: source.RegionFolding},
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			p, err := source.NewProjectWithFiles(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := p.ListFoldingRanges(tt.path)
+			if diff := cmp.Diff(tt.expectResult, got); diff != "" {
+				t.Errorf("ListFoldingRanges result diff (-expect +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package source
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+	"sigs.k8s.io/yaml"
+)
+
+// loadSampleInputs reads the sample input documents.
+// The documents which cannot be read are skipped, because they are only the hints for the completion and the hover.
+func loadSampleInputs(paths []string) []any {
+	var result []any
+	for _, in := range paths {
+		b, err := os.ReadFile(in)
+		if err != nil {
+			continue
+		}
+		var value any
+		if err := yaml.Unmarshal(b, &value); err != nil {
+			continue
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+// sampleInputs returns the sample input documents which are configured for the path.
+func (p *Project) sampleInputs(path string) []any {
+	return p.Config(path).inputs
+}
+
+// findSampleValues returns the values of the ref in the sample input documents.
+// The var in the ref refers all elements of the array and all values of the object.
+//
+//	input.request.object -> [{"kind": "Pod"}, {"kind": "Deployment"}]
+//	input.containers[_]  -> [{"name": "nginx"}, {"name": "redis"}]
+func findSampleValues(values []any, ref ast.Ref) []any {
+	for _, t := range ref {
+		next := make([]any, 0)
+		for _, v := range values {
+			switch v := v.(type) {
+			case map[string]any:
+				switch key := t.Value.(type) {
+				case ast.String:
+					if value, ok := v[string(key)]; ok {
+						next = append(next, value)
+					}
+				case ast.Var:
+					for _, value := range v {
+						next = append(next, value)
+					}
+				}
+			case []any:
+				switch key := t.Value.(type) {
+				case ast.Number:
+					if i, ok := key.Int(); ok && i >= 0 && i < len(v) {
+						next = append(next, v[i])
+					}
+				case ast.Var:
+					next = append(next, v...)
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// listSampleInputCompletionItems lists the keys in the sample input documents.
+//
+//	input.request.| -> kind, object, operation
+func (p *Project) listSampleInputCompletionItems(location *ast.Location, target *ast.Term) []CompletionItem {
+	if target == nil {
+		return nil
+	}
+	ref := p.rootDocumentRef(target, ast.InputRootDocument)
+	if len(ref) < 2 {
+		return nil
+	}
+
+	examples := make(map[string]any)
+	for _, v := range findSampleValues(p.sampleInputs(target.Loc().File), ref[1:len(ref)-1]) {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, value := range obj {
+			if _, ok := examples[key]; !ok {
+				examples[key] = value
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(examples))
+	for key := range examples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]CompletionItem, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, CompletionItem{
+			Label:    key,
+			Kind:     FieldItem,
+			Detail:   jsonDetail(examples[key]),
+			TextEdit: createTextEdit(location, key),
+		})
+	}
+	return result
+}
+
+// findSampleInputDocuments returns the example values of the ref in the sample input documents.
+func (p *Project) findSampleInputDocuments(term *ast.Term) []Document {
+	ref := p.rootDocumentRef(term, ast.InputRootDocument)
+	if len(ref) < 2 {
+		return nil
+	}
+
+	result := make([]Document, 0)
+	for _, v := range findSampleValues(p.sampleInputs(term.Loc().File), ref[1:]) {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			continue
+		}
+		result = append(result, Document{
+			Content:  string(b),
+			Language: "json",
+		})
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+)
+
+var sampleInputTestFiles = map[string]source.File{
+	source.ConfigFileName: {RawText: "input:\n  - examples/pod.json\n  - examples/deployment.yaml\n"},
+	"examples/pod.json":   {RawText: `{"request": {"kind": "Pod", "object": {"spec": {"containers": [{"name": "nginx"}]}}}}`},
+	"examples/deployment.yaml": {RawText: `request:
+  kind: Deployment
+  operation: CREATE
+`},
+}
+
+func TestProject_ListCompletionItems_SampleInput(t *testing.T) {
+	tests := map[string]struct {
+		src         string
+		expectItems []source.CompletionItem
+	}{
+		"Should list the keys in all sample inputs": {
+			src: `package main
+
+deny[msg] {
+	input.request.o|
+	msg := "denied"
+}`,
+			expectItems: []source.CompletionItem{
+				{Label: "object", Kind: source.FieldItem, Detail: `{"spec":{"containers":[{"name":"nginx"}]}}`, TextEdit: &source.TextEdit{Row: 4, Col: 16, Text: "object"}},
+				{Label: "operation", Kind: source.FieldItem, Detail: `"CREATE"`, TextEdit: &source.TextEdit{Row: 4, Col: 16, Text: "operation"}},
+			},
+		},
+		"Should list the keys of the array elements": {
+			src: `package main
+
+deny[msg] {
+	input.request.object.spec.containers[_].n|
+	msg := "denied"
+}`,
+			expectItems: []source.CompletionItem{
+				{Label: "name", Kind: source.FieldItem, Detail: `"nginx"`, TextEdit: &source.TextEdit{Row: 4, Col: 42, Text: "name"}},
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files := map[string]source.File{"src.rego": {RawText: tt.src}}
+			for path, f := range sampleInputTestFiles {
+				files[path] = f
+			}
+			project, location, _ := newProjectOnDisk(t, files)
+
+			got, err := project.ListCompletionItems(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expectItems, got); diff != "" {
+				t.Errorf("ListCompletionItems result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProject_TermDocument_SampleInput(t *testing.T) {
+	files := map[string]source.File{
+		"src.rego": {RawText: `package main
+
+deny[msg] {
+	input.request.ki|nd == "Pod"
+	msg := "denied"
+}`},
+	}
+	for path, f := range sampleInputTestFiles {
+		files[path] = f
+	}
+	project, location, _ := newProjectOnDisk(t, files)
+
+	got, err := project.TermDocument(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []source.Document{
+		{Content: `"Pod"`, Language: "json"},
+		{Content: `"Deployment"`, Language: "json"},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("TermDocument result diff (-expect, +got)\n%s", diff)
+	}
+}
+
+func TestProject_ReloadConfig_SampleInput(t *testing.T) {
+	files := map[string]source.File{
+		"src.rego": {RawText: `package main
+
+deny[msg] {
+	input.request.ki|nd == "Pod"
+	msg := "denied"
+}`},
+		source.ConfigFileName: {RawText: "input:\n  - examples/pod.json\n"},
+		"examples/pod.json":   {RawText: `{"request": {"kind": "Pod"}}`},
+	}
+	project, location, dir := newProjectOnDisk(t, files)
+
+	// The sample inputs are kept until the configuration is reloaded.
+	writeFiles(t, dir, map[string]string{"examples/pod.json": `{"request": {"kind": "Deployment"}}`})
+	tests := []struct {
+		reload bool
+		expect []source.Document
+	}{
+		{reload: false, expect: []source.Document{{Content: `"Pod"`, Language: "json"}}},
+		{reload: true, expect: []source.Document{{Content: `"Deployment"`, Language: "json"}}},
+	}
+	for _, tt := range tests {
+		if tt.reload {
+			if _, err := project.ReloadConfig(dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := project.TermDocument(location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tt.expect, got); diff != "" {
+			t.Errorf("TermDocument result diff (-expect, +got)\n%s", diff)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// LintSeverity is the severity of the lint check. LintOff disables the check.
+type LintSeverity int
+
+const (
+	LintOff LintSeverity = iota
+	LintError
+	LintWarning
+	LintInformation
+	LintHint
+)
+
+// ParseLintSeverity parses the severity in the configuration.
+func ParseLintSeverity(s string) (LintSeverity, error) {
+	switch s {
+	case "", "off":
+		return LintOff, nil
+	case "error":
+		return LintError, nil
+	case "warning":
+		return LintWarning, nil
+	case "information", "info":
+		return LintInformation, nil
+	case "hint":
+		return LintHint, nil
+	default:
+		return LintOff, fmt.Errorf("unknown lint severity %q", s)
+	}
+}
+
+// The lint checks which are reported by the strict mode of the compiler.
+const (
+	UnusedImportCheck      = "unused-import"
+	UnusedVariableCheck    = "unused-variable"
+	UnusedArgumentCheck    = "unused-argument"
+	DeprecatedBuiltinCheck = "deprecated-builtin"
+	ShadowingCheck         = "shadowing"
+)
+
+type LintIssue struct {
+	Check    string
+	Severity LintSeverity
+	Error    *ast.Error
+}
+
+// LintOptions is the lint settings of the client.
+// The lint settings in the configuration file take precedence over them.
+type LintOptions struct {
+	// Strict reports the errors of the strict mode as LintError unless the severity is configured.
+	Strict bool
+	// Severities is the severity of each lint check.
+	Severities map[string]LintSeverity
+}
+
+// SetLintOptions sets the lint settings of the client.
+func (p *Project) SetLintOptions(options LintOptions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lintOptions = options
+}
+
+// Lint lists the issues of the lint checks which are enabled in the configuration.
+// errs is the result of GetErrors. The issues are reported only when the policies are compiled without errors.
+func (p *Project) Lint(errs map[string]ast.Errors) map[string][]LintIssue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range errs {
+		if len(e) != 0 {
+			return nil
+		}
+	}
+	if !p.lintEnabled() {
+		return nil
+	}
+
+	result := make(map[string][]LintIssue)
+	for path, errs := range p.cache.GetStrictErrors() {
+		lint := p.config(path).Lint
+		for _, e := range errs {
+			check := lintCheck(e)
+			severity := p.lintSeverity(check, lint)
+			if severity == LintOff {
+				continue
+			}
+			result[path] = append(result[path], LintIssue{Check: check, Severity: severity, Error: e})
+		}
+	}
+	return result
+}
+
+// lintEnabled returns true when any lint check is enabled. The caller should hold the lock.
+func (p *Project) lintEnabled() bool {
+	if p.lintOptions.Strict {
+		return true
+	}
+	for _, s := range p.lintOptions.Severities {
+		if s != LintOff {
+			return true
+		}
+	}
+	for _, c := range p.configs {
+		for _, s := range c.Lint {
+			if severity, _ := ParseLintSeverity(s); severity != LintOff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lintSeverity returns the severity of the check. The caller should hold the lock.
+func (p *Project) lintSeverity(check string, lint map[string]string) LintSeverity {
+	if s, ok := lint[check]; ok && check != "" {
+		severity, _ := ParseLintSeverity(s)
+		return severity
+	}
+	if s, ok := p.lintOptions.Severities[check]; ok && check != "" {
+		return s
+	}
+	if p.lintOptions.Strict {
+		return LintError
+	}
+	return LintOff
+}
+
+// lintCheck returns the check name of the strict mode error.
+func lintCheck(e *ast.Error) string {
+	switch {
+	case strings.HasPrefix(e.Message, "import ") && strings.HasSuffix(e.Message, " unused"):
+		return UnusedImportCheck
+	case strings.HasPrefix(e.Message, "assigned var "), strings.HasPrefix(e.Message, "declared var "):
+		return UnusedVariableCheck
+	case strings.HasPrefix(e.Message, "unused argument "):
+		return UnusedArgumentCheck
+	case strings.HasPrefix(e.Message, "deprecated built-in function calls"):
+		return DeprecatedBuiltinCheck
+	case strings.Contains(e.Message, "must not shadow"):
+		return ShadowingCheck
+	}
+	return ""
+}
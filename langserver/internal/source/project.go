@@ -1,13 +1,22 @@
 package source
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+
 	"github.com/kitagry/regols/langserver/internal/cache"
 	"github.com/open-policy-agent/opa/ast"
 )
 
 type Project struct {
-	rootPath string
-	cache    *cache.GlobalCache
+	mu        sync.Mutex
+	rootPaths []string
+	cache     *cache.GlobalCache
+	// configs is the configuration for each workspace folder.
+	configs     map[string]*Config
+	lintOptions LintOptions
 }
 
 type File struct {
@@ -15,16 +24,34 @@ type File struct {
 	Version int
 }
 
-func NewProject(rootPath string) (*Project, error) {
-	cache, err := cache.NewGlobalCache(rootPath)
+// NewProject loads rego files under the root paths. Each root path is a workspace folder.
+// The configuration file in the root path is applied before loading.
+// When some configuration files are invalid, the project is returned with the ConfigErrors,
+// and those workspace folders are loaded with the default configuration.
+func NewProject(rootPaths ...string) (*Project, error) {
+	cache, err := cache.NewGlobalCache()
 	if err != nil {
 		return nil, err
 	}
 
-	return &Project{
-		rootPath: rootPath,
-		cache:    cache,
-	}, nil
+	p := &Project{
+		rootPaths: rootPaths,
+		cache:     cache,
+		configs:   make(map[string]*Config),
+	}
+	configErrs := make([]error, 0)
+	for _, r := range rootPaths {
+		if err := p.applyConfig(r); err != nil {
+			if !isConfigError(err) {
+				return nil, err
+			}
+			configErrs = append(configErrs, err)
+		}
+		if err := p.cache.LoadDir(r); err != nil {
+			return nil, err
+		}
+	}
+	return p, errors.Join(configErrs...)
 }
 
 func NewProjectWithFiles(files map[string]File) (*Project, error) {
@@ -39,14 +66,112 @@ func NewProjectWithFiles(files map[string]File) (*Project, error) {
 	}
 
 	return &Project{
-		cache: cache,
+		cache:   cache,
+		configs: make(map[string]*Config),
 	}, nil
 }
 
+// AddRootPath adds the workspace folder and loads rego files under it.
+// When the configuration file is invalid, the files are loaded with the default configuration and the ConfigError is returned.
+func (p *Project) AddRootPath(rootPath string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, r := range p.rootPaths {
+		if r == rootPath {
+			return nil
+		}
+	}
+
+	configErr := p.applyConfig(rootPath)
+	if !isConfigError(configErr) {
+		return configErr
+	}
+	p.rootPaths = append(p.rootPaths, rootPath)
+	// The capabilities are taken from the workspace folders.
+	p.cache.SetCapabilities(p.capabilities())
+
+	if err := p.cache.LoadDir(rootPath); err != nil {
+		return err
+	}
+	return configErr
+}
+
+// RemoveRootPath removes the workspace folder and returns the removed files.
+// The files which are contained in other workspace folders are kept.
+func (p *Project) RemoveRootPath(rootPath string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rootPaths := make([]string, 0, len(p.rootPaths))
+	for _, r := range p.rootPaths {
+		if r != rootPath {
+			rootPaths = append(rootPaths, r)
+		}
+	}
+	p.rootPaths = rootPaths
+	delete(p.configs, rootPath)
+	p.cache.SetIgnorePatterns(rootPath, nil)
+	p.cache.ClearIgnoreFiles(rootPath)
+	p.cache.SetDataRoots(rootPath, nil)
+	p.cache.SetCapabilities(p.capabilities())
+	p.cache.SetSchemaSet(p.schemaSet())
+	p.cache.SetInputSchemas(rootPath, nil)
+
+	removed := make([]string, 0)
+	for _, path := range p.cache.DeleteDir(rootPath) {
+		if p.inRootPaths(path) {
+			// restore the file which is contained in other workspace folders.
+			if err := p.cache.Load(path); err == nil {
+				continue
+			}
+		}
+		removed = append(removed, path)
+	}
+	return removed
+}
+
+func (p *Project) inRootPaths(path string) bool {
+	for _, r := range p.rootPaths {
+		if isUnder(path, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// RootPaths returns the workspace folders.
+func (p *Project) RootPaths() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.rootPaths...)
+}
+
+// UpdateFile updates the file which is opened in the client.
+// The opened file shadows the file on the disk until it is closed.
 func (p *Project) UpdateFile(path string, text string, version int) error {
-	p.cache.Put(path, text)
+	return p.cache.PutOverlay(path, text, version)
+}
+
+// CloseFile closes the file which is opened in the client and falls back to the file on the disk.
+// When the file doesn't exist on the disk, the file is removed.
+func (p *Project) CloseFile(path string) error {
+	p.cache.DeleteOverlay(path)
+
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			p.cache.Delete(path)
+			return nil
+		}
+		return err
+	}
+	return p.cache.Load(path)
+}
 
-	return nil
+// IsOpen returns true when the file is opened in the client.
+func (p *Project) IsOpen(path string) bool {
+	return p.cache.IsOpen(path)
 }
 
 func (p *Project) GetErrors(path string) map[string]ast.Errors {
@@ -62,10 +187,30 @@ func (p *Project) GetFile(path string) (string, bool) {
 	return policy.RawText, true
 }
 
+// GetFileVersion returns the version of the document which is sent from the client.
+func (p *Project) GetFileVersion(path string) (int, bool) {
+	policy := p.cache.Get(path)
+	if policy == nil {
+		return 0, false
+	}
+	return policy.Version, true
+}
+
+// DeleteFile deletes the file on the disk. The opened file is kept until it is closed.
 func (p *Project) DeleteFile(path string) {
 	p.cache.Delete(path)
 }
 
+// LoadFile reads the file from the disk. This is used when the file is changed outside of the client.
+func (p *Project) LoadFile(path string) error {
+	return p.cache.Load(path)
+}
+
+// DeleteDir deletes all files under the directory and returns the deleted paths.
+func (p *Project) DeleteDir(dir string) []string {
+	return p.cache.DeleteDir(dir)
+}
+
 func (p *Project) GetModule(path string) *ast.Module {
 	policy := p.cache.Get(path)
 	if policy == nil {
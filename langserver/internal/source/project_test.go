@@ -0,0 +1,146 @@
+package source_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/kitagry/regols/langserver/internal/source"
+)
+
+func TestProject_LoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "src.rego")
+	if err := os.WriteFile(path, []byte("package src"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("package lib"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := project.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := project.GetFile(path)
+	if !ok {
+		t.Fatalf("%s should be loaded", path)
+	}
+	if got != "package lib" {
+		t.Errorf("GetFile expect %q, but got %q", "package lib", got)
+	}
+}
+
+func TestProject_DeleteDir(t *testing.T) {
+	project, err := source.NewProjectWithFiles(map[string]source.File{
+		"/root/lib/a.rego":     {RawText: "package lib"},
+		"/root/lib/sub/b.rego": {RawText: "package lib.sub"},
+		"/root/libs/c.rego":    {RawText: "package libs"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := project.DeleteDir("/root/lib")
+	if diff := cmp.Diff([]string{"/root/lib/a.rego", "/root/lib/sub/b.rego"}, deleted, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("DeleteDir result diff (-expect, +got)\n%s", diff)
+	}
+
+	if _, ok := project.GetFile("/root/libs/c.rego"); !ok {
+		t.Errorf("/root/libs/c.rego should not be deleted")
+	}
+}
+
+func TestProject_CloseFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "src.rego")
+	if err := os.WriteFile(path, []byte("package src"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	newPath := filepath.Join(dir, "new.rego")
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := project.UpdateFile(path, "package lib", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := project.UpdateFile(newPath, "package new", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := project.GetFile(path); got != "package lib" {
+		t.Errorf("opened file should shadow the file on the disk, but got %q", got)
+	}
+
+	if err := project.CloseFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := project.GetFile(path); !ok || got != "package src" {
+		t.Errorf("closed file should fall back to the file on the disk, but got %q", got)
+	}
+	if project.IsOpen(path) {
+		t.Errorf("%s should be closed", path)
+	}
+
+	if err := project.CloseFile(newPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := project.GetFile(newPath); ok {
+		t.Errorf("%s doesn't exist on the disk, so it should be removed", newPath)
+	}
+}
+
+func TestProject_AddAndRemoveRootPath(t *testing.T) {
+	dir := t.TempDir()
+	policies := filepath.Join(dir, "policies")
+	lib := filepath.Join(dir, "lib")
+	for path, text := range map[string]string{
+		filepath.Join(policies, "src.rego"): "package src",
+		filepath.Join(lib, "lib.rego"):      "package lib",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	project, err := source.NewProject(policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	libPath := filepath.Join(lib, "lib.rego")
+	if _, ok := project.GetFile(libPath); ok {
+		t.Fatalf("%s should not be loaded before adding the root path", libPath)
+	}
+
+	if err := project.AddRootPath(lib); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := project.GetFile(libPath); !ok {
+		t.Errorf("%s should be loaded after adding the root path", libPath)
+	}
+	if diff := cmp.Diff([]string{policies, lib}, project.RootPaths()); diff != "" {
+		t.Errorf("RootPaths result diff (-expect, +got)\n%s", diff)
+	}
+
+	removed := project.RemoveRootPath(lib)
+	if diff := cmp.Diff([]string{libPath}, removed); diff != "" {
+		t.Errorf("RemoveRootPath result diff (-expect, +got)\n%s", diff)
+	}
+	if _, ok := project.GetFile(libPath); ok {
+		t.Errorf("%s should be removed after removing the root path", libPath)
+	}
+}
@@ -37,7 +37,10 @@ func (p *Project) LookupReferences(loc *ast.Location) ([]*ast.Location, error) {
 			return result[i].File < result[j].File
 		}
 
-		return result[i].Row < result[j].Row
+		if result[i].Row != result[j].Row {
+			return result[i].Row < result[j].Row
+		}
+		return result[i].Col < result[j].Col
 	})
 
 	return result, nil
@@ -46,8 +49,13 @@ func (p *Project) LookupReferences(loc *ast.Location) ([]*ast.Location, error) {
 func (p *Project) findReferences(term *ast.Term) []*ast.Location {
 	// Target term is defined in the definedRule
 	definedRule := p.findRuleForTerm(term.Loc())
-	isDefinedInRule := p.findDefinitionInRule(term, definedRule) != nil
+	definition := p.findDefinitionInRule(term, definedRule)
+	isDefinedInRule := definition != nil
 	if isDefinedInRule {
+		// The variable defined in the comprehension or every is referenced only in it.
+		if scopes := findScopes(definition.Loc(), definedRule); len(scopes) > 0 {
+			return p.findReferencesInScope(term, scopes[0])
+		}
 		return p.findReferencesInRule(term, definedRule, isDefinedInRule)
 	}
 
@@ -79,10 +87,14 @@ func (p *Project) findReferences(term *ast.Term) []*ast.Location {
 	for _, pkg := range p.cache.GetPackages() {
 		modules := p.cache.FindPolicies(pkg)
 		for _, module := range modules {
-			isDefinedPackage := definedPackage.Equal(module.Package)
 			isSameWithCalledPackage := policy.Module.Package.Equal(module.Package)
-			_, ok := findImportedPkg(definedPackage, module)
-			if !ok && !isSameWithCalledPackage && !isDefinedPackage {
+			// The term which doesn't have the definition is referenced only in the same package. e.g. input
+			isDefinedPackage, isImported := false, false
+			if definedPackage != nil {
+				isDefinedPackage = definedPackage.Equal(module.Package)
+				_, isImported = findImportedPkg(definedPackage, module)
+			}
+			if !isImported && !isSameWithCalledPackage && !isDefinedPackage {
 				continue
 			}
 
@@ -228,19 +240,18 @@ func findPackageName(term *ast.Term, termModule *ast.Module) (*ast.Term, bool) {
 func (p *Project) findReferencesInRule(term *ast.Term, rule *ast.Rule, isDefinedInRule bool) []*ast.Location {
 	result := make([]*ast.Location, 0)
 
-	if rule.Head.Name.Equal(term.Value) {
+	if ruleName(rule).Equal(term.Value) {
 		if !isDefinedInRule {
 			return result
 		}
-		loc := &ast.Location{
-			Row:    rule.Head.Location.Row,
-			Col:    rule.Head.Location.Col,
-			Text:   []byte(rule.Head.Name),
-			File:   rule.Head.Location.File,
-			Offset: rule.Head.Location.Offset,
-		}
-		result = append(result, loc)
+		result = append(result, ruleNameLocation(rule))
+	}
+
+	refs := p.findReferencesInTerms(term, rule.Head.Ref()[1:])
+	if len(refs) > 0 && !isDefinedInRule {
+		return result
 	}
+	result = append(result, refs...)
 
 	if rule.Head.Key != nil {
 		keys := p.findReferencesInTerm(term, rule.Head.Key)
@@ -275,21 +286,45 @@ func (p *Project) findReferencesInRule(term *ast.Term, rule *ast.Rule, isDefined
 			}
 		}
 
-		terms := make([]*ast.Location, 0)
-		switch t := b.Terms.(type) {
-		case *ast.Term:
-			terms = p.findReferencesInTerm(term, t)
-		case []*ast.Term:
-			terms = p.findReferencesInTerms(term, t)
-		default:
-			fmt.Fprintf(os.Stderr, "type: %T", b.Terms)
-		}
-		result = append(result, terms...)
+		result = append(result, p.findReferencesInExpr(term, b)...)
 	}
 
 	return result
 }
 
+func (p *Project) findReferencesInBody(term *ast.Term, body ast.Body) []*ast.Location {
+	result := make([]*ast.Location, 0)
+	for _, b := range body {
+		result = append(result, p.findReferencesInExpr(term, b)...)
+	}
+	return result
+}
+
+func (p *Project) findReferencesInExpr(term *ast.Term, expr *ast.Expr) []*ast.Location {
+	result := make([]*ast.Location, 0)
+	switch t := expr.Terms.(type) {
+	case *ast.Term:
+		result = append(result, p.findReferencesInTerm(term, t)...)
+	case []*ast.Term:
+		result = append(result, p.findReferencesInTerms(term, t)...)
+	case *ast.SomeDecl:
+		result = append(result, p.findReferencesInTerms(term, t.Symbols)...)
+	case *ast.Every:
+		result = append(result, p.findReferencesInTerms(term, everyVars(t))...)
+		result = append(result, p.findReferencesInTerm(term, t.Domain)...)
+		result = append(result, p.findReferencesInBody(term, t.Body)...)
+	default:
+		fmt.Fprintf(os.Stderr, "type: %T", expr.Terms)
+	}
+
+	// with input.x as y
+	for _, w := range expr.With {
+		result = append(result, p.findReferencesInTerm(term, w.Target)...)
+		result = append(result, p.findReferencesInTerm(term, w.Value)...)
+	}
+	return result
+}
+
 func (p *Project) findReferencesInTerms(target *ast.Term, terms []*ast.Term) []*ast.Location {
 	result := make([]*ast.Location, 0)
 	for _, term := range terms {
@@ -318,6 +353,23 @@ func (p *Project) findReferencesInTerm(target *ast.Term, term *ast.Term) []*ast.
 			result = append(result, p.findReferencesInTerm(target, v.Elem(i))...)
 		}
 		return result
+	case ast.Object:
+		result := make([]*ast.Location, 0)
+		for _, key := range v.Keys() {
+			result = append(result, p.findReferencesInTerm(target, key)...)
+			result = append(result, p.findReferencesInTerm(target, v.Get(key))...)
+		}
+		return result
+	case ast.Set:
+		return p.findReferencesInTerms(target, v.Slice())
+	case *ast.ArrayComprehension:
+		return append(p.findReferencesInTerm(target, v.Term), p.findReferencesInBody(target, v.Body)...)
+	case *ast.SetComprehension:
+		return append(p.findReferencesInTerm(target, v.Term), p.findReferencesInBody(target, v.Body)...)
+	case *ast.ObjectComprehension:
+		result := p.findReferencesInTerm(target, v.Key)
+		result = append(result, p.findReferencesInTerm(target, v.Value)...)
+		return append(result, p.findReferencesInBody(target, v.Body)...)
 	case ast.Var:
 		if target.Equal(term) {
 			return []*ast.Location{term.Location}
@@ -16,6 +16,8 @@ func TestLookupReferences(t *testing.T) {
 		files        map[string]source.File
 		expectResult []*ast.Location
 		expectErr    error
+		// location is used when the cursor can't be written with "|". e.g. comprehension
+		location createLocationFunc
 	}{
 		"Should list self": {
 			files: map[string]source.File{
@@ -145,6 +147,36 @@ i|s_hello(msg) {
 				},
 			},
 		},
+		"Should list rule which is used on the right side of the unification": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+limit = 3
+
+allow {
+	x = lim|it
+	count(input.users) < x
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    3,
+					Col:    1,
+					Offset: len("package src\n\n"),
+					Text:   []byte("limit"),
+					File:   "src.rego",
+				},
+				{
+					Row:    6,
+					Col:    6,
+					Offset: len("package src\n\nlimit = 3\n\nallow {\n\tx = "),
+					Text:   []byte("limit"),
+					File:   "src.rego",
+				},
+			},
+		},
 		"Should list rule's key": {
 			files: map[string]source.File{
 				"src.rego": {
@@ -865,11 +897,104 @@ fuga() {
 				},
 			},
 		},
+		"Should list references only in the comprehension": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+violation[msg] {
+	xs := [x | x := input.a[_]]
+	ys := [x | x := input.b[_]]
+	msg := {"xs": xs, "ys": ys}
+}`,
+				},
+			},
+			location: createLocation(4, 13, "src.rego"),
+			expectResult: []*ast.Location{
+				{
+					Row:    4,
+					Col:    9,
+					Offset: len("package main\n\nviolation[msg] {\n	xs := ["),
+					Text:   []byte("x"),
+					File:   "src.rego",
+				},
+				{
+					Row:    4,
+					Col:    13,
+					Offset: len("package main\n\nviolation[msg] {\n	xs := [x | "),
+					Text:   []byte("x"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should list references in the object": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+violation[msg] {
+	xs| := input.a
+	msg := {"xs": xs}
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    4,
+					Col:    2,
+					Offset: len("package main\n\nviolation[msg] {\n	"),
+					Text:   []byte("xs"),
+					File:   "src.rego",
+				},
+				{
+					Row:    5,
+					Col:    16,
+					Offset: len("package main\n\nviolation[msg] {\n	xs := input.a\n	msg := {\"xs\": "),
+					Text:   []byte("xs"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should list references only in every": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package main
+
+import future.keywords.every
+
+allow {
+	every x| in input.a {
+		x > 1
+	}
+	x := 1
+}`,
+				},
+			},
+			expectResult: []*ast.Location{
+				{
+					Row:    6,
+					Col:    8,
+					Offset: len("package main\n\nimport future.keywords.every\n\nallow {\n	every "),
+					Text:   []byte("x"),
+					File:   "src.rego",
+				},
+				{
+					Row:    7,
+					Col:    3,
+					Offset: len("package main\n\nimport future.keywords.every\n\nallow {\n	every x in input.a {\n		"),
+					Text:   []byte("x"),
+					File:   "src.rego",
+				},
+			},
+		},
 	}
 
 	for n, tt := range tests {
 		t.Run(n, func(t *testing.T) {
 			files, location, err := helper.GetAstLocation(tt.files)
+			if tt.location != nil {
+				files, location, err = tt.files, tt.location(tt.files), nil
+			}
 			if err != nil {
 				t.Fatal(err)
 			}
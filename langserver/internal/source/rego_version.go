@@ -0,0 +1,68 @@
+package source
+
+import (
+	"path/filepath"
+
+	"github.com/kitagry/regols/langserver/internal/cache"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/format"
+)
+
+// RegoVersion is the syntax version which is used to parse the policies.
+type RegoVersion = cache.RegoVersion
+
+const (
+	RegoVersionAuto             = cache.RegoVersionAuto
+	RegoVersionV0               = cache.RegoVersionV0
+	RegoVersionV0FutureKeywords = cache.RegoVersionV0FutureKeywords
+	RegoVersionV1               = cache.RegoVersionV1
+)
+
+// ParseRegoVersion parses the rego version in the configuration. e.g. "v0", "v1"
+func ParseRegoVersion(s string) (RegoVersion, error) {
+	return cache.ParseRegoVersion(s)
+}
+
+// SetRegoVersion sets the rego version of the files under the directory.
+// The empty directory sets the default version of the project.
+func (p *Project) SetRegoVersion(dir string, version RegoVersion) error {
+	return p.cache.SetRegoVersion(dir, version)
+}
+
+// ResetRegoVersion resets the rego version of the directory which is set by SetRegoVersion.
+// When the directory is a workspace folder, the rego version in its configuration file is restored.
+func (p *Project) ResetRegoVersion(dir string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	version := RegoVersionAuto
+	for root, c := range p.configs {
+		if filepath.Clean(root) == filepath.Clean(dir) {
+			// The configuration is validated when it is loaded.
+			version, _ = ParseRegoVersion(c.RegoVersion)
+		}
+	}
+	return p.cache.SetRegoVersion(dir, version)
+}
+
+// Format formats the file with the rego version of the file.
+func (p *Project) Format(path string) ([]byte, error) {
+	rawText, ok := p.GetFile(path)
+	if !ok {
+		return nil, nil
+	}
+
+	module, err := ast.ParseModuleWithOpts(path, rawText, p.cache.ParserOptions(path, rawText))
+	if err != nil {
+		return nil, err
+	}
+
+	// The v0 formatter keeps contains and if only when they are imported,
+	// so the policy which uses all future keywords without the import is formatted as v1.
+	opts := format.Opts{RegoVersion: ast.RegoV0}
+	switch p.cache.RegoVersion(path) {
+	case RegoVersionV0FutureKeywords, RegoVersionV1:
+		opts.RegoVersion = ast.RegoV1
+	}
+	return format.AstWithOpts(module, opts)
+}
@@ -0,0 +1,155 @@
+package source_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+)
+
+func TestProject_SetRegoVersion(t *testing.T) {
+	tests := map[string]struct {
+		files         map[string]source.File
+		dir           string
+		version       source.RegoVersion
+		expectErrPath []string
+	}{
+		"Should parse v1 policy with auto version when rego.v1 is imported": {
+			files: map[string]source.File{
+				"src.rego": {RawText: "package src\n\nimport rego.v1\n\ndeny contains msg if msg := \"x\"\n"},
+			},
+			version:       source.RegoVersionAuto,
+			expectErrPath: []string{},
+		},
+		"Should require v1 syntax with auto version when rego.v1 is imported": {
+			files: map[string]source.File{
+				"src.rego": {RawText: "package src\n\nimport rego.v1\n\ndeny[msg] {\n\tmsg := \"x\"\n}\n"},
+			},
+			version:       source.RegoVersionAuto,
+			expectErrPath: []string{"src.rego"},
+		},
+		"Should parse v1 policy without import": {
+			files: map[string]source.File{
+				"src.rego": {RawText: "package src\n\ndeny contains msg if msg := \"x\"\n"},
+			},
+			version:       source.RegoVersionV1,
+			expectErrPath: []string{},
+		},
+		"Should parse future keywords without import": {
+			files: map[string]source.File{
+				"src.rego": {RawText: "package src\n\ndeny contains msg if {\n\tsome msg in input\n}\n"},
+			},
+			version:       source.RegoVersionV0FutureKeywords,
+			expectErrPath: []string{},
+		},
+		"Should parse only the files under the directory as v1": {
+			files: map[string]source.File{
+				"v1/src.rego": {RawText: "package v1\n\ndeny contains msg if msg := \"x\"\n"},
+				"v0/src.rego": {RawText: "package v0\n\ndeny contains msg if msg := \"x\"\n"},
+			},
+			dir:           "v1",
+			version:       source.RegoVersionV1,
+			expectErrPath: []string{"v0/src.rego"},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			project, err := source.NewProjectWithFiles(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := project.SetRegoVersion(tt.dir, tt.version); err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0)
+			for path := range tt.files {
+				for p, errs := range project.GetErrors(path) {
+					if p == path && len(errs) > 0 {
+						got = append(got, p)
+					}
+				}
+			}
+			if diff := cmp.Diff(tt.expectErrPath, got); diff != "" {
+				t.Errorf("GetErrors result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProject_ResetRegoVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		source.ConfigFileName: "regoVersion: v1\n",
+		"src.rego":            "package src\n\ndeny contains msg if msg := \"x\"\n",
+	})
+
+	project, err := source.NewProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := project.SetRegoVersion(dir, source.RegoVersionV0); err != nil {
+		t.Fatal(err)
+	}
+	if err := project.ResetRegoVersion(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "src.rego")
+	if errs := project.GetErrors(path)[path]; len(errs) != 0 {
+		t.Errorf("%s should be parsed with the rego version of %s, but got %v", path, source.ConfigFileName, errs)
+	}
+}
+
+func TestProject_Format(t *testing.T) {
+	tests := map[string]struct {
+		rawText string
+		version source.RegoVersion
+		expect  string
+	}{
+		"Should format v0 policy": {
+			rawText: "package src\ndeny[msg] { msg := \"x\" }\n",
+			version: source.RegoVersionAuto,
+			expect:  "package src\n\ndeny[msg] {\n\tmsg := \"x\"\n}\n",
+		},
+		"Should keep future keywords of v0 policy": {
+			rawText: "package src\ndeny contains msg if { msg := \"x\" }\n",
+			version: source.RegoVersionV0FutureKeywords,
+			expect:  "package src\n\ndeny contains msg if msg := \"x\"\n",
+		},
+		"Should keep imported future keywords of v0 policy": {
+			rawText: "package src\nimport future.keywords\ndeny contains msg if { msg := \"x\" }\n",
+			version: source.RegoVersionV0,
+			expect:  "package src\n\nimport future.keywords\n\ndeny contains msg if msg := \"x\"\n",
+		},
+		"Should format v1 policy": {
+			rawText: "package src\ndeny contains msg if { msg := \"x\" }\n",
+			version: source.RegoVersionV1,
+			expect:  "package src\n\ndeny contains msg if msg := \"x\"\n",
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			project, err := source.NewProjectWithFiles(map[string]source.File{"src.rego": {RawText: tt.rawText}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := project.SetRegoVersion("", tt.version); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := project.Format("src.rego")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expect, string(got)); diff != "" {
+				t.Errorf("Format result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
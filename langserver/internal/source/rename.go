@@ -0,0 +1,196 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+var (
+	ErrNotRenamable = errors.New("the symbol cannot be renamed")
+	ErrInvalidName  = errors.New("invalid name")
+)
+
+// futureKeywords are not included in ast.Keywords, but they cannot be used as a name
+// when the module imports future.keywords or rego.v1.
+var futureKeywords = []string{"in", "every", "contains", "if"}
+
+// PrepareRename returns the location of the symbol which will be renamed.
+func (p *Project) PrepareRename(loc *ast.Location) (*ast.Location, error) {
+	locations, err := p.lookupRenameLocations(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range locations {
+		if l.File == loc.File && in(loc, l) {
+			return l, nil
+		}
+	}
+	return nil, ErrNotRenamable
+}
+
+// Rename returns the locations which should be replaced with newName.
+func (p *Project) Rename(loc *ast.Location, newName string) ([]*ast.Location, error) {
+	if !isValidName(newName) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, newName)
+	}
+
+	locations, err := p.lookupRenameLocations(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range locations {
+		if l.File == loc.File && in(loc, l) {
+			return locations, nil
+		}
+	}
+	return nil, ErrNotRenamable
+}
+
+func isValidName(name string) bool {
+	if !ast.IsVarCompatibleString(name) || ast.IsKeyword(name) {
+		return false
+	}
+	for _, k := range futureKeywords {
+		if k == name {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Project) lookupRenameLocations(loc *ast.Location) ([]*ast.Location, error) {
+	definitions, err := p.LookupDefinition(loc)
+	if err != nil {
+		return nil, err
+	}
+	// builtin functions, input and data don't have definitions in the workspace.
+	if len(definitions) == 0 {
+		return nil, ErrNotRenamable
+	}
+
+	result, err := p.LookupReferences(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	path, index, ok := p.findDataPath(definitions[0])
+	if ok {
+		// When the cursor is on `data.lib.rule`, references can't be found from the term.
+		// So, we search references from the definition too.
+		refs, err := p.LookupReferences(definitions[0])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, refs...)
+		result = append(result, definitions...)
+		result = append(result, p.findDataRefReferences(path, index)...)
+
+		// The references of the package contain the location of `package` keyword.
+		result = filterLocationsByName(result, path[index])
+	}
+
+	return uniqueLocations(result), nil
+}
+
+// findDataPath returns the data path of the rule or the package which is defined at the location.
+// index is the position of the renamed name in the path.
+//
+//	package lib      -> data.lib, 1
+//	import data.lib  -> data.lib, 1
+//	rule { ... }     -> data.lib.rule, 2
+func (p *Project) findDataPath(definition *ast.Location) (path ast.Ref, index int, ok bool) {
+	module := p.GetModule(definition.File)
+	if module == nil {
+		return nil, 0, false
+	}
+
+	pkgPath := module.Package.Path
+	if last := pkgPath[len(pkgPath)-1].Location; last != nil && last.Offset == definition.Offset {
+		return pkgPath, len(pkgPath) - 1, true
+	}
+
+	for _, imp := range module.Imports {
+		ref, ok := imp.Path.Value.(ast.Ref)
+		if !ok || !isImportTerm(imp.Path) {
+			continue
+		}
+		if last := ref[len(ref)-1].Location; last != nil && last.Offset == definition.Offset {
+			return ref, len(ref) - 1, true
+		}
+	}
+
+	for _, rule := range module.Rules {
+		if rule.Head.Location.Offset == definition.Offset {
+			return pkgPath.Append(ast.StringTerm(ruleName(rule).String())), len(pkgPath), true
+		}
+	}
+	return nil, 0, false
+}
+
+// findDataRefReferences lists the locations of path[index] in all refs which start with path.
+//
+//	data.lib.rule
+//	         ^ path is data.lib.rule and index is 2
+func (p *Project) findDataRefReferences(path ast.Ref, index int) []*ast.Location {
+	result := make([]*ast.Location, 0)
+	for _, module := range p.cache.GetModules() {
+		ast.WalkRefs(module, func(ref ast.Ref) bool {
+			if len(ref) <= index || !ast.DefaultRootDocument.Equal(ref[0]) {
+				return false
+			}
+			for i := 1; i <= index; i++ {
+				if ref[i].Value.Compare(path[i].Value) != 0 {
+					return false
+				}
+			}
+			if ref[index].Location != nil {
+				result = append(result, ref[index].Location)
+			}
+			return false
+		})
+	}
+	return result
+}
+
+func filterLocationsByName(locations []*ast.Location, name *ast.Term) []*ast.Location {
+	str, ok := name.Value.(ast.String)
+	if !ok {
+		return locations
+	}
+
+	result := make([]*ast.Location, 0, len(locations))
+	for _, l := range locations {
+		text := string(l.Text)
+		if text == string(str) || text == strconv.Quote(string(str)) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func uniqueLocations(locations []*ast.Location) []*ast.Location {
+	exists := make(map[string]struct{})
+	result := make([]*ast.Location, 0, len(locations))
+	for _, l := range locations {
+		key := fmt.Sprintf("%s-%d", l.File, l.Offset)
+		if _, ok := exists[key]; ok {
+			continue
+		}
+		exists[key] = struct{}{}
+		result = append(result, l)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		return result[i].Offset < result[j].Offset
+	})
+	return result
+}
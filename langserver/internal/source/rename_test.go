@@ -0,0 +1,291 @@
+package source_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/kitagry/regols/langserver/internal/source/helper"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+func TestProject_Rename(t *testing.T) {
+	tests := map[string]struct {
+		files        map[string]source.File
+		newName      string
+		expectResult []*ast.Location
+		expectErr    error
+	}{
+		"Should rename local variable": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	m := "hello"
+	msg := m|
+}`,
+				},
+			},
+			newName: "message",
+			expectResult: []*ast.Location{
+				{
+					Row:    4,
+					Col:    2,
+					Offset: len("package src\n\nviolation[msg] {\n	"),
+					Text:   []byte("m"),
+					File:   "src.rego",
+				},
+				{
+					Row:    5,
+					Col:    9,
+					Offset: len("package src\n\nviolation[msg] {\n	m := \"hello\"\n	msg := "),
+					Text:   []byte("m"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should rename rule in other package and data ref": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib
+
+violation[msg] {
+	lib.is_h|ello(msg)
+	data.lib.is_hello(msg)
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+default is_hello(msg) = false
+
+is_hello(msg) {
+	msg == "hello"
+}`,
+				},
+			},
+			newName: "is_world",
+			expectResult: []*ast.Location{
+				{
+					Row:    3,
+					Col:    9,
+					Offset: len("package lib\n\ndefault "),
+					Text:   []byte("is_hello"),
+					File:   "lib.rego",
+				},
+				{
+					Row:    5,
+					Col:    1,
+					Offset: len("package lib\n\ndefault is_hello(msg) = false\n\n"),
+					Text:   []byte("is_hello"),
+					File:   "lib.rego",
+				},
+				{
+					Row:    6,
+					Col:    6,
+					Offset: len("package src\n\nimport data.lib\n\nviolation[msg] {\n	lib."),
+					Text:   []byte("is_hello"),
+					File:   "src.rego",
+				},
+				{
+					Row:    7,
+					Col:    11,
+					Offset: len("package src\n\nimport data.lib\n\nviolation[msg] {\n	lib.is_hello(msg)\n	data.lib."),
+					Text:   []byte("is_hello"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should rename rule from data ref": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib as l
+
+violation[msg] {
+	l.is_hello(msg)
+	data.lib.is_h|ello(msg)
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+is_hello(msg) {
+	msg == "hello"
+}`,
+				},
+			},
+			newName: "is_world",
+			expectResult: []*ast.Location{
+				{
+					Row:    3,
+					Col:    1,
+					Offset: len("package lib\n\n"),
+					Text:   []byte("is_hello"),
+					File:   "lib.rego",
+				},
+				{
+					Row:    6,
+					Col:    4,
+					Offset: len("package src\n\nimport data.lib as l\n\nviolation[msg] {\n	l."),
+					Text:   []byte("is_hello"),
+					File:   "src.rego",
+				},
+				{
+					Row:    7,
+					Col:    11,
+					Offset: len("package src\n\nimport data.lib as l\n\nviolation[msg] {\n	l.is_hello(msg)\n	data.lib."),
+					Text:   []byte("is_hello"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should rename package": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib
+
+violation[msg] {
+	l|ib.is_hello(msg)
+	data.lib.is_hello(msg)
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+is_hello(msg) {
+	msg == "hello"
+}`,
+				},
+			},
+			newName: "util",
+			expectResult: []*ast.Location{
+				{
+					Row:    1,
+					Col:    9,
+					Offset: len("package "),
+					Text:   []byte("lib"),
+					File:   "lib.rego",
+				},
+				{
+					Row:    3,
+					Col:    13,
+					Offset: len("package src\n\nimport data."),
+					Text:   []byte("lib"),
+					File:   "src.rego",
+				},
+				{
+					Row:    6,
+					Col:    2,
+					Offset: len("package src\n\nimport data.lib\n\nviolation[msg] {\n	"),
+					Text:   []byte("lib"),
+					File:   "src.rego",
+				},
+				{
+					Row:    7,
+					Col:    7,
+					Offset: len("package src\n\nimport data.lib\n\nviolation[msg] {\n	lib.is_hello(msg)\n	data."),
+					Text:   []byte("lib"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should rename import alias only in the file": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib as l
+
+violation[msg] {
+	l|.is_hello(msg)
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+is_hello(msg) {
+	msg == "hello"
+}`,
+				},
+			},
+			newName: "util",
+			expectResult: []*ast.Location{
+				{
+					Row:    3,
+					Col:    20,
+					Offset: len("package src\n\nimport data.lib as "),
+					Text:   []byte("l"),
+					File:   "src.rego",
+				},
+				{
+					Row:    6,
+					Col:    2,
+					Offset: len("package src\n\nimport data.lib as l\n\nviolation[msg] {\n	"),
+					Text:   []byte("l"),
+					File:   "src.rego",
+				},
+			},
+		},
+		"Should not rename builtin function": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	c|ount([]) == 0
+}`,
+				},
+			},
+			newName:   "length",
+			expectErr: source.ErrNotRenamable,
+		},
+		"Should not rename to keyword": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	m := "hello"
+	msg := m|
+}`,
+				},
+			},
+			newName:   "some",
+			expectErr: source.ErrInvalidName,
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files, location, err := helper.GetAstLocation(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := source.NewProjectWithFiles(files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := p.Rename(location, tt.newName)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Rename should return error expect %v, but got %v", tt.expectErr, err)
+			}
+
+			if diff := cmp.Diff(tt.expectResult, got, cmp.Comparer(func(x, y []*ast.Location) bool {
+				return reflect.DeepEqual(x, y)
+			})); diff != "" {
+				t.Errorf("Rename result diff (-expect +got):\n%s", diff)
+			}
+		})
+	}
+}
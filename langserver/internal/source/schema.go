@@ -0,0 +1,356 @@
+package source
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"sigs.k8s.io/yaml"
+)
+
+// maxSchemaRefDepth is the max depth to resolve $ref. This prevents the infinite loop of the recursive schema.
+const maxSchemaRefDepth = 32
+
+// schema is the JSON schema which is loaded from the schema directory.
+type schema struct {
+	ref   ast.Ref
+	value any
+}
+
+// loadSchemas reads the JSON and YAML schemas under the directories.
+// The ref of the schema is the path relative to the directory. e.g. k8s/pod.json -> schema.k8s.pod
+func loadSchemas(dirs []string) ([]schema, error) {
+	var result []schema
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := filepath.Ext(path)
+			if d.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+				return nil
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var value any
+			if err := yaml.Unmarshal(b, &value); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+
+			rel, err := filepath.Rel(dir, strings.TrimSuffix(path, ext))
+			if err != nil {
+				return err
+			}
+			ref := ast.SchemaRootRef.Copy()
+			for _, s := range strings.Split(filepath.ToSlash(rel), "/") {
+				ref = append(ref, ast.StringTerm(s))
+			}
+			result = append(result, schema{ref: ref, value: value})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// schemaSet returns the schemas of all workspace folders. The caller should hold the lock.
+func (p *Project) schemaSet() *ast.SchemaSet {
+	var ss *ast.SchemaSet
+	for _, r := range p.rootPaths {
+		c, ok := p.configs[r]
+		if !ok {
+			continue
+		}
+		for _, s := range c.schemas {
+			if ss == nil {
+				ss = ast.NewSchemaSet()
+			}
+			ss.Put(s.ref, s.value)
+		}
+	}
+	return ss
+}
+
+// inputSchema is the schema of the input document which is applied to the policy.
+type inputSchema struct {
+	// path is the path of the document which the schema describes. e.g. input, input.request
+	path  ast.Ref
+	value any
+}
+
+// inputSchemas returns the input schemas of the location. The more specific schema comes first.
+// The METADATA schemas take precedence over the configured input schema.
+func (p *Project) inputSchemas(loc *ast.Location) []inputSchema {
+	result := make([]inputSchema, 0)
+
+	module := p.GetModule(loc.File)
+	if module == nil {
+		return nil
+	}
+
+	var rule *ast.Rule
+	for _, r := range module.Rules {
+		if in(loc, r.Loc()) {
+			rule = r
+			break
+		}
+	}
+
+	if rule != nil && len(module.Annotations) != 0 {
+		as, errs := ast.BuildAnnotationSet(p.cache.GetModules())
+		if len(errs) != 0 {
+			as, _ = ast.BuildAnnotationSet([]*ast.Module{module})
+		}
+		for _, ref := range as.Chain(rule) {
+			// The chain contains the paths which have no annotations.
+			if ref.Annotations == nil {
+				continue
+			}
+			for _, s := range ref.Annotations.Schemas {
+				if !s.Path.HasPrefix(ast.InputRootRef) {
+					continue
+				}
+				var value any
+				if s.Definition != nil {
+					value = *s.Definition
+				} else if s.Schema != nil {
+					value = p.cache.GetSchema(s.Schema)
+				}
+				if value != nil {
+					result = append(result, inputSchema{path: s.Path, value: value})
+				}
+			}
+		}
+	}
+
+	if ref, ok := p.cache.InputSchemaRef(loc.File); ok {
+		if value := p.cache.GetSchema(ref); value != nil {
+			result = append(result, inputSchema{path: ast.InputRootRef, value: value})
+		}
+	}
+	return result
+}
+
+// findInputSchema returns the schema of the input ref and its root schema which is used to resolve $ref.
+func (p *Project) findInputSchema(loc *ast.Location, ref ast.Ref) (root, value any, ok bool) {
+	for _, s := range p.inputSchemas(loc) {
+		if !ref.HasPrefix(s.path) {
+			continue
+		}
+
+		value := resolveSchemaRef(s.value, s.value)
+		for _, t := range ref[len(s.path):] {
+			value = schemaProperty(s.value, value, t.Value)
+			if value == nil {
+				break
+			}
+		}
+		if value != nil {
+			return s.value, value, true
+		}
+	}
+	return nil, nil, false
+}
+
+// listInputCompletionItems lists the properties of the input schema.
+//
+//	input.request.| -> kind, object, operation
+func (p *Project) listInputCompletionItems(location *ast.Location, target *ast.Term) []CompletionItem {
+	if target == nil {
+		return nil
+	}
+	ref := p.rootDocumentRef(target, ast.InputRootDocument)
+	if len(ref) < 2 {
+		return nil
+	}
+
+	root, value, ok := p.findInputSchema(target.Loc(), ref[:len(ref)-1])
+	if !ok {
+		return nil
+	}
+
+	properties := schemaProperties(root, value)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]CompletionItem, 0, len(names))
+	for _, name := range names {
+		result = append(result, CompletionItem{
+			Label:    name,
+			Kind:     FieldItem,
+			Detail:   schemaDetail(root, properties[name]),
+			TextEdit: createTextEdit(location, name),
+		})
+	}
+	return result
+}
+
+// findInputDocuments returns the type and the description of the input field.
+func (p *Project) findInputDocuments(term *ast.Term) []Document {
+	ref := p.rootDocumentRef(term, ast.InputRootDocument)
+	if len(ref) < 2 {
+		return nil
+	}
+
+	root, value, ok := p.findInputSchema(term.Loc(), ref)
+	if !ok {
+		return nil
+	}
+	result := []Document{
+		{
+			Content:  fmt.Sprintf("%s: %s", ref, schemaType(root, value)),
+			Language: "rego",
+		},
+	}
+	if d := schemaDescription(root, value); d != "" {
+		result = append(result, Document{Content: d, Language: "markdown"})
+	}
+	return result
+}
+
+// resolveSchemaRef resolves the local $ref of the schema. e.g. #/definitions/pod
+func resolveSchemaRef(root, value any) any {
+	for i := 0; i < maxSchemaRefDepth; i++ {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return value
+		}
+		value = jsonPointer(root, strings.TrimPrefix(ref, "#"))
+	}
+	return nil
+}
+
+// jsonPointer returns the value of the JSON pointer. e.g. /definitions/pod
+func jsonPointer(root any, pointer string) any {
+	value := root
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// schemaAlternatives returns the schema and the schemas in allOf, anyOf and oneOf.
+func schemaAlternatives(root, value any) []map[string]any {
+	result := make([]map[string]any, 0)
+	var walk func(value any, depth int)
+	walk = func(value any, depth int) {
+		m, ok := resolveSchemaRef(root, value).(map[string]any)
+		if !ok || depth > maxSchemaRefDepth {
+			return
+		}
+		result = append(result, m)
+		for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+			list, _ := m[key].([]any)
+			for _, s := range list {
+				walk(s, depth+1)
+			}
+		}
+	}
+	walk(value, 0)
+	return result
+}
+
+// schemaProperties returns the properties of the object schema.
+func schemaProperties(root, value any) map[string]any {
+	result := make(map[string]any)
+	for _, s := range schemaAlternatives(root, value) {
+		properties, _ := s["properties"].(map[string]any)
+		for name, p := range properties {
+			if _, ok := result[name]; !ok {
+				result[name] = p
+			}
+		}
+	}
+	return result
+}
+
+// schemaProperty returns the schema of the key.
+// The key which is not a string refers the items of the array or the additional properties of the object.
+func schemaProperty(root, value any, key ast.Value) any {
+	for _, s := range schemaAlternatives(root, value) {
+		if name, ok := key.(ast.String); ok {
+			properties, _ := s["properties"].(map[string]any)
+			if p, ok := properties[string(name)]; ok {
+				return resolveSchemaRef(root, p)
+			}
+		}
+		if items, ok := s["items"].(map[string]any); ok {
+			if _, ok := key.(ast.String); !ok {
+				return resolveSchemaRef(root, items)
+			}
+		}
+		if additional, ok := s["additionalProperties"].(map[string]any); ok {
+			return resolveSchemaRef(root, additional)
+		}
+	}
+	return nil
+}
+
+// schemaType returns the type of the schema. e.g. string, object, string|null
+func schemaType(root, value any) string {
+	types := make([]string, 0)
+	for _, s := range schemaAlternatives(root, value) {
+		switch t := s["type"].(type) {
+		case string:
+			types = append(types, t)
+		case []any:
+			for _, tt := range t {
+				if str, ok := tt.(string); ok {
+					types = append(types, str)
+				}
+			}
+		}
+	}
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, "|")
+}
+
+// schemaDescription returns the first description of the schema.
+func schemaDescription(root, value any) string {
+	for _, s := range schemaAlternatives(root, value) {
+		if d, ok := s["description"].(string); ok && d != "" {
+			return d
+		}
+	}
+	return ""
+}
+
+// schemaDetail returns the type and the description of the schema for the completion detail.
+func schemaDetail(root, value any) string {
+	detail := schemaType(root, value)
+	if d := schemaDescription(root, value); d != "" {
+		detail += "\n\n" + d
+	}
+	return detail
+}
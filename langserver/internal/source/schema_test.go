@@ -0,0 +1,255 @@
+package source_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+var schemaTestFiles = map[string]source.File{
+	source.ConfigFileName: {RawText: "schemas:\n  - schemas\ninputSchemas:\n  \"mapped/**\": schema.admission\n"},
+	"schemas/admission.json": {RawText: `{
+  "type": "object",
+  "properties": {
+    "request": {"$ref": "#/definitions/request"}
+  },
+  "definitions": {
+    "request": {
+      "type": "object",
+      "properties": {
+        "kind": {"type": "string", "description": "The kind of the object."},
+        "object": {"type": "object"},
+        "operation": {"type": "string"}
+      }
+    }
+  }
+}`},
+}
+
+func TestProject_ListCompletionItems_InputSchema(t *testing.T) {
+	tests := map[string]struct {
+		path        string
+		src         string
+		expectItems []source.CompletionItem
+	}{
+		"Should list the properties of the METADATA schema": {
+			path: "src.rego",
+			src: `package main
+
+# METADATA
+# schemas:
+#   - input: schema.admission
+deny[msg] {
+	input.request.o|
+	msg := "denied"
+}`,
+			expectItems: []source.CompletionItem{
+				{Label: "object", Kind: source.FieldItem, Detail: "object", TextEdit: &source.TextEdit{Row: 7, Col: 16, Text: "object"}},
+				{Label: "operation", Kind: source.FieldItem, Detail: "string", TextEdit: &source.TextEdit{Row: 7, Col: 16, Text: "operation"}},
+			},
+		},
+		"Should list the properties of the configured schema": {
+			path: "mapped/src.rego",
+			src: `package main
+
+deny[msg] {
+	input.request.k|
+	msg := "denied"
+}`,
+			expectItems: []source.CompletionItem{
+				{Label: "kind", Kind: source.FieldItem, Detail: "string\n\nThe kind of the object.", TextEdit: &source.TextEdit{Row: 4, Col: 16, Text: "kind"}},
+			},
+		},
+		"Should list the properties of the inline schema": {
+			path: "src.rego",
+			src: `package main
+
+# METADATA
+# schemas:
+#   - input.request: {"type": "object", "properties": {"uid": {"type": "string"}}}
+deny[msg] {
+	input.request.u|
+	msg := "denied"
+}`,
+			expectItems: []source.CompletionItem{
+				{Label: "uid", Kind: source.FieldItem, Detail: "string", TextEdit: &source.TextEdit{Row: 7, Col: 16, Text: "uid"}},
+			},
+		},
+		"Should list the properties of the configured schema with unrelated METADATA": {
+			path: "mapped/src.rego",
+			src: `package main
+
+# METADATA
+# title: Allow the request
+allow := true
+
+deny[msg] {
+	input.request.k|
+	msg := "denied"
+}`,
+			expectItems: []source.CompletionItem{
+				{Label: "kind", Kind: source.FieldItem, Detail: "string\n\nThe kind of the object.", TextEdit: &source.TextEdit{Row: 8, Col: 16, Text: "kind"}},
+			},
+		},
+		"Should not list the properties without schema": {
+			path: "src.rego",
+			src: `package main
+
+deny[msg] {
+	input.request.k|
+	msg := "denied"
+}`,
+			expectItems: []source.CompletionItem{},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files := map[string]source.File{tt.path: {RawText: tt.src}}
+			for path, f := range schemaTestFiles {
+				files[path] = f
+			}
+			project, location, _ := newProjectOnDisk(t, files)
+
+			got, err := project.ListCompletionItems(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expectItems, got); diff != "" {
+				t.Errorf("ListCompletionItems result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProject_TermDocument_InputSchema(t *testing.T) {
+	tests := map[string]struct {
+		src string
+	}{
+		"Should document the property of the configured schema": {
+			src: `package main
+
+deny[msg] {
+	input.request.ki|nd == "Pod"
+	msg := "denied"
+}`,
+		},
+		"Should document the property of the configured schema with unrelated METADATA": {
+			src: `package main
+
+# METADATA
+# title: Allow the request
+allow := true
+
+deny[msg] {
+	input.request.ki|nd == "Pod"
+	msg := "denied"
+}`,
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files := map[string]source.File{"mapped/src.rego": {RawText: tt.src}}
+			for path, f := range schemaTestFiles {
+				files[path] = f
+			}
+			project, location, _ := newProjectOnDisk(t, files)
+
+			got, err := project.TermDocument(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expect := []source.Document{
+				{Content: "input.request.kind: string", Language: "rego"},
+				{Content: "The kind of the object.", Language: "markdown"},
+			}
+			if diff := cmp.Diff(expect, got); diff != "" {
+				t.Errorf("TermDocument result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProject_GetErrors_InputSchema(t *testing.T) {
+	tests := map[string]struct {
+		path      string
+		src       string
+		expectErr bool
+	}{
+		"Should report the undefined field of the METADATA schema": {
+			path: "src.rego",
+			src: `package main
+
+# METADATA
+# schemas:
+#   - input: schema.admission
+deny[msg] {
+	input.request.kinds == "Pod"
+	msg := "denied"
+}`,
+			expectErr: true,
+		},
+		"Should report the undefined field of the configured schema": {
+			path: "mapped/src.rego",
+			src: `package main
+
+deny[msg] {
+	input.request.kinds == "Pod"
+	msg := "denied"
+}`,
+			expectErr: true,
+		},
+		"Should not report the defined field": {
+			path: "mapped/src.rego",
+			src: `package main
+
+deny[msg] {
+	input.request.kind == "Pod"
+	msg := "denied"
+}`,
+			expectErr: false,
+		},
+		"Should not report without schema": {
+			path: "src.rego",
+			src: `package main
+
+deny[msg] {
+	input.request.kinds == "Pod"
+	msg := "denied"
+}`,
+			expectErr: false,
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{tt.path: tt.src}
+			for path, f := range schemaTestFiles {
+				files[path] = f.RawText
+			}
+			writeFiles(t, dir, files)
+
+			project, err := source.NewProject(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(dir, tt.path)
+			errs := project.GetErrors(path)[path]
+			if got := len(errs) != 0; got != tt.expectErr {
+				t.Fatalf("GetErrors expect error %v, but got %v", tt.expectErr, errs)
+			}
+			for _, e := range errs {
+				if e.Code != ast.TypeErr {
+					t.Errorf("GetErrors expect type error, but got %v", e)
+				}
+			}
+		})
+	}
+}
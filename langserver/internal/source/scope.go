@@ -0,0 +1,99 @@
+package source
+
+import "github.com/open-policy-agent/opa/ast"
+
+// scope is the block which has its own variables in the rule.
+//
+//	[x | x := input[_]]
+//	every k, v in input.xs { ... }
+type scope struct {
+	location *ast.Location
+	// vars are declared by the scope itself. e.g. k and v of `every k, v in xs`
+	vars []*ast.Term
+	body ast.Body
+	// node is *ast.Term for the comprehension and *ast.Expr for every.
+	node any
+}
+
+// findScopes returns the scopes which contain the location from the innermost.
+func findScopes(loc *ast.Location, rule *ast.Rule) []scope {
+	result := make([]scope, 0)
+	vis := ast.NewGenericVisitor(func(x any) bool {
+		var s scope
+		switch v := x.(type) {
+		case *ast.Term:
+			body := comprehensionBody(v)
+			if body == nil {
+				return false
+			}
+			s = scope{location: v.Location, body: body, node: v}
+		case *ast.Expr:
+			every, ok := v.Terms.(*ast.Every)
+			if !ok {
+				return false
+			}
+			// every x in xs
+			//            ^ domain is out of the scope
+			if every.Domain.Location != nil && in(loc, every.Domain.Location) {
+				return false
+			}
+			s = scope{location: v.Location, vars: everyVars(every), body: every.Body, node: v}
+		default:
+			return false
+		}
+
+		if s.location == nil || !in(loc, s.location) {
+			return true
+		}
+		result = append([]scope{s}, result...)
+		return false
+	})
+	vis.Walk(rule)
+	return result
+}
+
+func comprehensionBody(term *ast.Term) ast.Body {
+	switch v := term.Value.(type) {
+	case *ast.ArrayComprehension:
+		return v.Body
+	case *ast.SetComprehension:
+		return v.Body
+	case *ast.ObjectComprehension:
+		return v.Body
+	}
+	return nil
+}
+
+// everyVars returns the variables declared by every.
+//
+//	every x in xs    -> x
+//	every k, v in xs -> k, v
+func everyVars(every *ast.Every) []*ast.Term {
+	if every.Key == nil {
+		return []*ast.Term{every.Value}
+	}
+	return []*ast.Term{every.Key, every.Value}
+}
+
+// someDeclVars returns the variables declared by the symbol of some.
+//
+//	some x           -> x
+//	some x in xs     -> internal.member_2(x, xs)       -> x
+//	some k, v in xs  -> internal.member_3(k, v, xs)    -> k, v
+func someDeclVars(symbol *ast.Term) []*ast.Term {
+	if call, ok := symbol.Value.(ast.Call); ok && len(call) > 2 {
+		return call[1 : len(call)-1]
+	}
+	return []*ast.Term{symbol}
+}
+
+// findReferencesInScope finds the references in the scope.
+func (p *Project) findReferencesInScope(term *ast.Term, s scope) []*ast.Location {
+	switch node := s.node.(type) {
+	case *ast.Term:
+		return p.findReferencesInTerm(term, node)
+	case *ast.Expr:
+		return p.findReferencesInExpr(term, node)
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package source
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+type SemanticToken struct {
+	Location  *ast.Location
+	Type      SemanticTokenType
+	Modifiers SemanticTokenModifier
+}
+
+type SemanticTokenType int
+
+const (
+	NamespaceToken SemanticTokenType = iota
+	RuleToken
+	FunctionToken
+	ParameterToken
+	VariableToken
+)
+
+// SemanticTokenModifier is the bit set of the modifiers.
+type SemanticTokenModifier int
+
+const (
+	DeclarationModifier SemanticTokenModifier = 1 << iota
+	DefaultLibraryModifier
+)
+
+// ListSemanticTokens lists the tokens which are classified by the resolution of the names.
+// The tokens are sorted by the offset.
+func (p *Project) ListSemanticTokens(path string) []SemanticToken {
+	module := p.GetModule(path)
+	if module == nil {
+		return nil
+	}
+
+	c := &semanticTokenCollector{
+		project: p,
+		module:  module,
+		tokens:  make([]SemanticToken, 0),
+	}
+	c.collectPackage(module.Package)
+	for _, imp := range module.Imports {
+		c.collectImport(imp)
+	}
+	for _, rule := range module.Rules {
+		c.collectRule(rule)
+	}
+
+	sort.SliceStable(c.tokens, func(i, j int) bool {
+		return c.tokens[i].Location.Offset < c.tokens[j].Location.Offset
+	})
+	return c.tokens
+}
+
+type semanticTokenCollector struct {
+	project *Project
+	module  *ast.Module
+	tokens  []SemanticToken
+
+	// rule is the rule which is being collected.
+	rule *ast.Rule
+	// operators are the terms which are called as function.
+	operators map[*ast.Term]struct{}
+}
+
+func (c *semanticTokenCollector) add(loc *ast.Location, typ SemanticTokenType, modifiers SemanticTokenModifier) {
+	if loc == nil || len(loc.Text) == 0 || strings.ContainsAny(string(loc.Text), "\"`\n") {
+		return
+	}
+	c.tokens = append(c.tokens, SemanticToken{Location: loc, Type: typ, Modifiers: modifiers})
+}
+
+func (c *semanticTokenCollector) collectPackage(pkg *ast.Package) {
+	// pkg.Path[0] is "data" generated by the parser.
+	for _, t := range pkg.Path[1:] {
+		c.add(t.Location, NamespaceToken, DeclarationModifier)
+	}
+}
+
+func (c *semanticTokenCollector) collectImport(imp *ast.Import) {
+	ref, ok := imp.Path.Value.(ast.Ref)
+	if !ok {
+		return
+	}
+	c.add(ref[0].Location, VariableToken, DefaultLibraryModifier)
+	for _, t := range ref[1:] {
+		c.add(t.Location, NamespaceToken, 0)
+	}
+}
+
+func (c *semanticTokenCollector) collectRule(rule *ast.Rule) {
+	if ruleName(rule) != "" && rule.Head.Location != nil {
+		c.add(ruleNameLocation(rule), ruleTokenType(rule), DeclarationModifier)
+	}
+
+	for r := rule; r != nil; r = r.Else {
+		c.rule = r
+		c.operators = make(map[*ast.Term]struct{})
+		ast.WalkExprs(r.Body, func(e *ast.Expr) bool {
+			if terms, ok := e.Terms.([]*ast.Term); ok && len(terms) > 0 {
+				c.operators[terms[0]] = struct{}{}
+			}
+			return false
+		})
+		ast.WalkTerms(r.Body, func(t *ast.Term) bool {
+			if call, ok := t.Value.(ast.Call); ok && len(call) > 0 {
+				c.operators[call[0]] = struct{}{}
+			}
+			return false
+		})
+
+		// The head of else rule is the copy of the parent's head except the value.
+		if r == rule {
+			c.collectTerms(r.Head.Args)
+			c.collectTerms(r.Head.Ref()[1:])
+			if r.Head.Key != nil {
+				c.collectTerms(r.Head.Key)
+			}
+		}
+		if r.Head.Value != nil && r.Head.Value.Location != nil {
+			c.collectTerms(r.Head.Value)
+		}
+		c.collectTerms(r.Body)
+	}
+}
+
+func (c *semanticTokenCollector) collectTerms(x any) {
+	ast.WalkTerms(x, func(t *ast.Term) bool {
+		switch v := t.Value.(type) {
+		case ast.Var:
+			c.collectVar(t, v)
+			return true
+		case ast.Ref:
+			c.collectRef(t, v)
+			return true
+		}
+		return false
+	})
+}
+
+func (c *semanticTokenCollector) collectVar(term *ast.Term, v ast.Var) {
+	if v.IsWildcard() || v.IsGenerated() {
+		return
+	}
+	if v.Equal(ast.InputRootDocument.Value) || v.Equal(ast.DefaultRootDocument.Value) {
+		c.add(term.Location, VariableToken, DefaultLibraryModifier)
+		return
+	}
+
+	// The args are bound before the head value. e.g. `f(x) = x`
+	def := c.project.findDefinitionInTerms(term, c.rule.Head.Args)
+	if def == nil {
+		def = c.project.findDefinitionInRule(term, c.rule)
+	}
+	if def != nil {
+		var modifiers SemanticTokenModifier
+		if def.Location.Offset == term.Location.Offset {
+			modifiers = DeclarationModifier
+		}
+		typ := VariableToken
+		if isArg(def, c.rule) {
+			typ = ParameterToken
+		}
+		c.add(term.Location, typ, modifiers)
+		return
+	}
+
+	if imp := findImportOutsidePolicy(v.String(), c.module.Imports); imp != nil && isImportName(imp, v) {
+		c.add(term.Location, NamespaceToken, 0)
+		return
+	}
+
+	if rules := findRulesByName(c.module, v.String()); len(rules) > 0 {
+		c.add(term.Location, ruleTokenType(rules[0]), 0)
+		return
+	}
+
+	c.add(term.Location, VariableToken, 0)
+}
+
+func (c *semanticTokenCollector) collectRef(term *ast.Term, ref ast.Ref) {
+	head, ok := ref[0].Value.(ast.Var)
+	if !ok {
+		return
+	}
+
+	// operator like `:=` and `==`
+	if term.Location != nil && string(term.Location.Text) != ref.String() && c.isOperator(term) {
+		if _, ok := ast.BuiltinMap[ref.String()]; ok {
+			return
+		}
+	}
+
+	rest := 1
+	switch {
+	case c.project.findDefinitionInRule(ref[0], c.rule) != nil:
+		c.collectVar(ref[0], head)
+	case head.Equal(ast.DefaultRootDocument.Value):
+		c.collectVar(ref[0], head)
+		rest = c.collectPackageRef(ref, 1)
+	case c.isOperator(term) && c.isBuiltin(ref):
+		// io.jwt.decode is highlighted as one token.
+		if term.Location != nil && string(term.Location.Text) == ref.String() {
+			c.add(term.Location, FunctionToken, DefaultLibraryModifier)
+		}
+		rest = len(ref)
+	default:
+		imp := findImportOutsidePolicy(head.String(), c.module.Imports)
+		if imp == nil || !isImportName(imp, head) {
+			c.collectVar(ref[0], head)
+			break
+		}
+		c.add(ref[0].Location, NamespaceToken, 0)
+		if pkg, ok := imp.Path.Value.(ast.Ref); ok && len(ref) > 1 {
+			if _, ok := ref[1].Value.(ast.String); ok {
+				c.collectRuleRef(pkg, ref[1])
+				rest = 2
+			}
+		}
+	}
+
+	for _, t := range ref[rest:] {
+		c.collectTerms(t)
+	}
+}
+
+// collectPackageRef collects the tokens of data.lib.rule and returns the index after the rule.
+func (c *semanticTokenCollector) collectPackageRef(ref ast.Ref, start int) int {
+	var pkg ast.Ref
+	for _, p := range c.project.cache.GetPackages() {
+		if len(p) > len(pkg) && len(p) <= len(ref) && ref[:len(p)].Equal(p) {
+			pkg = p
+		}
+	}
+	if pkg == nil {
+		return start
+	}
+
+	for _, t := range ref[start:len(pkg)] {
+		c.add(t.Location, NamespaceToken, 0)
+	}
+	if len(ref) == len(pkg) {
+		return len(pkg)
+	}
+	c.collectRuleRef(pkg, ref[len(pkg)])
+	return len(pkg) + 1
+}
+
+func (c *semanticTokenCollector) collectRuleRef(pkg ast.Ref, name *ast.Term) {
+	str, ok := name.Value.(ast.String)
+	if !ok {
+		return
+	}
+	for _, mod := range c.project.cache.FindPolicies(pkg) {
+		if rules := findRulesByName(mod, string(str)); len(rules) > 0 {
+			c.add(name.Location, ruleTokenType(rules[0]), 0)
+			return
+		}
+	}
+}
+
+func (c *semanticTokenCollector) isOperator(term *ast.Term) bool {
+	_, ok := c.operators[term]
+	return ok
+}
+
+func (c *semanticTokenCollector) isBuiltin(ref ast.Ref) bool {
+	b, ok := ast.BuiltinMap[ref.String()]
+	return ok && b.Infix == ""
+}
+
+func ruleTokenType(rule *ast.Rule) SemanticTokenType {
+	if len(rule.Head.Args) > 0 {
+		return FunctionToken
+	}
+	return RuleToken
+}
+
+func findRulesByName(module *ast.Module, name string) []*ast.Rule {
+	result := make([]*ast.Rule, 0)
+	for _, r := range module.Rules {
+		if ruleName(r).String() == name {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func isArg(term *ast.Term, rule *ast.Rule) bool {
+	for r := rule; r != nil; r = r.Else {
+		found := false
+		ast.WalkTerms(r.Head.Args, func(t *ast.Term) bool {
+			found = found || t == term
+			return found
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// isImportName reports whether v refers the import. findImportOutsidePolicy matches the suffix of the import path.
+func isImportName(imp *ast.Import, v ast.Var) bool {
+	if imp.Alias != "" {
+		return imp.Alias.Equal(v)
+	}
+	ref, ok := imp.Path.Value.(ast.Ref)
+	if !ok {
+		return false
+	}
+	str, ok := ref[len(ref)-1].Value.(ast.String)
+	return ok && string(str) == v.String()
+}
@@ -0,0 +1,116 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+)
+
+func TestProject_ListSemanticTokens(t *testing.T) {
+	type token struct {
+		Text      string
+		Row       int
+		Col       int
+		Type      source.SemanticTokenType
+		Modifiers source.SemanticTokenModifier
+	}
+
+	tests := map[string]struct {
+		files        map[string]source.File
+		path         string
+		expectResult []token
+	}{
+		"Should classify names": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib
+
+violation[msg] {
+	is_bad(input.user)
+	msg := sprintf("%s", [lib.name])
+}
+
+is_bad(user) {
+	user.bad
+	data.lib.name == "x"
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+name := "lib"`,
+				},
+			},
+			path: "src.rego",
+			expectResult: []token{
+				{Text: "src", Row: 1, Col: 9, Type: source.NamespaceToken, Modifiers: source.DeclarationModifier},
+				{Text: "data", Row: 3, Col: 8, Type: source.VariableToken, Modifiers: source.DefaultLibraryModifier},
+				{Text: "lib", Row: 3, Col: 13, Type: source.NamespaceToken},
+				{Text: "violation", Row: 5, Col: 1, Type: source.RuleToken, Modifiers: source.DeclarationModifier},
+				{Text: "msg", Row: 5, Col: 11, Type: source.VariableToken, Modifiers: source.DeclarationModifier},
+				{Text: "is_bad", Row: 6, Col: 2, Type: source.FunctionToken},
+				{Text: "input", Row: 6, Col: 9, Type: source.VariableToken, Modifiers: source.DefaultLibraryModifier},
+				{Text: "msg", Row: 7, Col: 2, Type: source.VariableToken},
+				{Text: "sprintf", Row: 7, Col: 9, Type: source.FunctionToken, Modifiers: source.DefaultLibraryModifier},
+				{Text: "lib", Row: 7, Col: 24, Type: source.NamespaceToken},
+				{Text: "name", Row: 7, Col: 28, Type: source.RuleToken},
+				{Text: "is_bad", Row: 10, Col: 1, Type: source.FunctionToken, Modifiers: source.DeclarationModifier},
+				{Text: "user", Row: 10, Col: 8, Type: source.ParameterToken, Modifiers: source.DeclarationModifier},
+				{Text: "user", Row: 11, Col: 2, Type: source.ParameterToken},
+				{Text: "data", Row: 12, Col: 2, Type: source.VariableToken, Modifiers: source.DefaultLibraryModifier},
+				{Text: "lib", Row: 12, Col: 7, Type: source.NamespaceToken},
+				{Text: "name", Row: 12, Col: 11, Type: source.RuleToken},
+			},
+		},
+		"Should classify local variables in else": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+f(x) = y {
+	y := x
+} else = x {
+	true
+}`,
+				},
+			},
+			path: "src.rego",
+			expectResult: []token{
+				{Text: "src", Row: 1, Col: 9, Type: source.NamespaceToken, Modifiers: source.DeclarationModifier},
+				{Text: "f", Row: 3, Col: 1, Type: source.FunctionToken, Modifiers: source.DeclarationModifier},
+				{Text: "x", Row: 3, Col: 3, Type: source.ParameterToken, Modifiers: source.DeclarationModifier},
+				{Text: "y", Row: 3, Col: 8, Type: source.VariableToken, Modifiers: source.DeclarationModifier},
+				{Text: "y", Row: 4, Col: 2, Type: source.VariableToken},
+				{Text: "x", Row: 4, Col: 7, Type: source.ParameterToken},
+				{Text: "x", Row: 5, Col: 10, Type: source.ParameterToken},
+			},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			p, err := source.NewProjectWithFiles(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tokens := p.ListSemanticTokens(tt.path)
+			got := make([]token, len(tokens))
+			for i, tok := range tokens {
+				got[i] = token{
+					Text:      string(tok.Location.Text),
+					Row:       tok.Location.Row,
+					Col:       tok.Location.Col,
+					Type:      tok.Type,
+					Modifiers: tok.Modifiers,
+				}
+			}
+			if diff := cmp.Diff(tt.expectResult, got); diff != "" {
+				t.Errorf("ListSemanticTokens result diff (-expect +got):\n%s", diff)
+			}
+		})
+	}
+}
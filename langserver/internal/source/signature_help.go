@@ -0,0 +1,235 @@
+package source
+
+import (
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/types"
+)
+
+type SignatureHelp struct {
+	Signatures []Signature
+	// ActiveParameter is the index of the argument which the cursor is on.
+	ActiveParameter int
+}
+
+type Signature struct {
+	Label string
+	// Documentation is the markdown document.
+	Documentation string
+	Parameters    []SignatureParameter
+}
+
+type SignatureParameter struct {
+	// Label is the substring of Signature.Label.
+	Label         string
+	Documentation string
+}
+
+// SignatureHelp returns the signatures of the function which is called at the location.
+//
+// The function call is searched from the raw text, because the module can't be parsed while typing the arguments.
+//
+//	sprintf("%s", [x|
+//	^ active parameter is 1
+func (p *Project) SignatureHelp(location *ast.Location) *SignatureHelp {
+	policy := p.cache.Get(location.File)
+	if policy == nil {
+		return nil
+	}
+
+	name, activeParameter, ok := findCallAt(policy.RawText, location.Offset)
+	if !ok {
+		return nil
+	}
+
+	var signatures []Signature
+	if b, ok := ast.BuiltinMap[name]; ok && b.Decl != nil && b.Infix == "" {
+		signatures = []Signature{createBuiltinSignature(b)}
+	} else if policy.Module != nil {
+		signatures = p.listFunctionSignatures(name, policy.Module)
+	}
+	if len(signatures) == 0 {
+		return nil
+	}
+
+	return &SignatureHelp{
+		Signatures:      signatures,
+		ActiveParameter: activeParameter,
+	}
+}
+
+// findCallAt returns the function name and the index of the argument at the offset.
+func findCallAt(rawText string, offset int) (name string, argIndex int, ok bool) {
+	if offset > len(rawText) {
+		offset = len(rawText)
+	}
+
+	text := maskCommentsAndStrings(rawText[:offset])
+	depth := 0
+	for i := offset - 1; i >= 0; i-- {
+		switch c := text[i]; c {
+		case ')', ']', '}':
+			depth++
+		case '(', '[', '{':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if c == '{' && isRuleBodyStart(text[:i]) {
+				return "", 0, false
+			}
+			if c == '(' {
+				if name := callName(text[:i]); name != "" {
+					return name, argIndex, true
+				}
+			}
+			// The cursor is in the array, set, object or parenthesis in the arguments.
+			argIndex = 0
+		case ',':
+			if depth == 0 {
+				argIndex++
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// maskCommentsAndStrings replaces the comments and the contents of the string literals with spaces,
+// so that the brackets, the commas and the quotes in them are not counted. The offsets are kept.
+//
+//	f("(", x) # g(y, -> f(" ", x)
+func maskCommentsAndStrings(text string) string {
+	b := []byte(text)
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '#':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case '"':
+			for i++; i < len(b) && b[i] != '"' && b[i] != '\n'; i++ {
+				if b[i] == '\\' && i+1 < len(b) && b[i+1] != '\n' {
+					// the escaped character. e.g. \" and \\
+					b[i] = ' '
+					i++
+				}
+				b[i] = ' '
+			}
+		case '`':
+			for i++; i < len(b) && b[i] != '`'; i++ {
+				if b[i] != '\n' {
+					b[i] = ' '
+				}
+			}
+		}
+	}
+	return string(b)
+}
+
+// isRuleBodyStart reports whether "{" after the text starts the rule body.
+//
+//	allow {
+//	f(x) {
+//	allow if {
+func isRuleBodyStart(text string) bool {
+	text = strings.TrimRight(text, " \t")
+	if text == "" {
+		return true
+	}
+	c := text[len(text)-1]
+	return isNameChar(c) || c == ')' || c == ']'
+}
+
+// callName returns the name just before "(".
+func callName(text string) string {
+	i := len(text)
+	for i > 0 && (isNameChar(text[i-1]) || text[i-1] == '.') {
+		i--
+	}
+	name := text[i:]
+	if name == "" || ast.IsKeyword(name) || (name[0] >= '0' && name[0] <= '9') {
+		return ""
+	}
+	return name
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func createBuiltinSignature(b *ast.Builtin) Signature {
+	args := b.Decl.NamedFuncArgs().Args
+	params := make([]SignatureParameter, len(args))
+	labels := make([]string, len(args))
+	for i, a := range args {
+		labels[i] = a.String()
+		params[i] = SignatureParameter{Label: labels[i]}
+		if named, ok := a.(*types.NamedType); ok {
+			params[i].Documentation = named.Descr
+		}
+	}
+	return Signature{
+		Label:         b.Name + "(" + strings.Join(labels, ", ") + ")",
+		Documentation: builtinSignatureDocumentation(b),
+		Parameters:    params,
+	}
+}
+
+// listFunctionSignatures lists the signatures of the functions defined in the workspace.
+//
+//	f     -> f in the same package
+//	lib.f -> f in the imported package
+//	data.lib.f
+func (p *Project) listFunctionSignatures(name string, module *ast.Module) []Signature {
+	parts := strings.Split(name, ".")
+	var pkg ast.Ref
+	switch {
+	case len(parts) == 1:
+		pkg = module.Package.Path
+	case parts[0] == ast.DefaultRootDocument.String():
+		pkg = ast.Ref{ast.DefaultRootDocument}
+		for _, part := range parts[1 : len(parts)-1] {
+			pkg = append(pkg, ast.StringTerm(part))
+		}
+	default:
+		imp := findImportOutsidePolicy(parts[0], module.Imports)
+		if imp == nil {
+			return nil
+		}
+		impPath, ok := imp.Path.Value.(ast.Ref)
+		if !ok {
+			return nil
+		}
+		pkg = impPath.Copy()
+		for _, part := range parts[1 : len(parts)-1] {
+			pkg = append(pkg, ast.StringTerm(part))
+		}
+	}
+
+	word := parts[len(parts)-1]
+	result := make([]Signature, 0)
+	labels := make(map[string]struct{})
+	for _, mod := range p.cache.FindPolicies(pkg) {
+		for _, rule := range mod.Rules {
+			if ruleName(rule).String() != word || len(rule.Head.Args) == 0 {
+				continue
+			}
+			label := word + rule.Head.Args.String()
+			if _, ok := labels[label]; ok {
+				continue
+			}
+			labels[label] = struct{}{}
+
+			params := make([]SignatureParameter, len(rule.Head.Args))
+			for i, arg := range rule.Head.Args {
+				params[i] = SignatureParameter{Label: arg.String()}
+			}
+			result = append(result, Signature{
+				Label:      label,
+				Parameters: params,
+			})
+		}
+	}
+	return result
+}
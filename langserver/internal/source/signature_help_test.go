@@ -0,0 +1,206 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/kitagry/regols/langserver/internal/source/helper"
+)
+
+func TestProject_SignatureHelp(t *testing.T) {
+	tests := map[string]struct {
+		files        map[string]source.File
+		expectResult *source.SignatureHelp
+	}{
+		"Should show builtin function signature": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	msg := sprintf("%s, %s", [input.a, |])
+}`,
+				},
+			},
+			expectResult: &source.SignatureHelp{
+				Signatures: []source.Signature{
+					{
+						Label: "sprintf(format: string, values: array[any])",
+						Documentation: "Returns the given string, formatted.\n\n" +
+							"**Returns**\n\n" +
+							"- `output: string`: `format` formatted by the values in `values`\n\n" +
+							"*Categories: strings*\n\n" +
+							"[Policy reference](https://www.openpolicyagent.org/docs/latest/policy-reference/#builtin-strings-sprintf)",
+						Parameters: []source.SignatureParameter{
+							{Label: "format: string", Documentation: "string with formatting verbs"},
+							{Label: "values: array[any]", Documentation: "arguments to format into formatting verbs"},
+						},
+					},
+				},
+				ActiveParameter: 1,
+			},
+		},
+		"Should show builtin function signature while typing": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	count(|
+}`,
+				},
+			},
+			expectResult: &source.SignatureHelp{
+				Signatures: []source.Signature{
+					{
+						Label: "count(collection: any<string, array[any], object[any: any], set[any]>)",
+						Documentation: "Count takes a collection or string and returns the number of elements (or characters) in it.\n\n" +
+							"**Returns**\n\n" +
+							"- `n: number`: the count of elements, key/val pairs, or characters, respectively.\n\n" +
+							"*Categories: aggregates*\n\n" +
+							"[Policy reference](https://www.openpolicyagent.org/docs/latest/policy-reference/#builtin-aggregates-count)",
+						Parameters: []source.SignatureParameter{
+							{Label: "collection: any<string, array[any], object[any: any], set[any]>", Documentation: "the set/array/object/string to be counted"},
+						},
+					},
+				},
+			},
+		},
+		"Should show function signature in other package": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib
+
+violation[msg] {
+	lib.is_admin(input.user, "(,", |)
+}`,
+				},
+				"lib.rego": {
+					RawText: `package lib
+
+is_admin(user, role, group) {
+	user.role == role
+}`,
+				},
+			},
+			expectResult: &source.SignatureHelp{
+				Signatures: []source.Signature{
+					{
+						Label: "is_admin(user, role, group)",
+						Parameters: []source.SignatureParameter{
+							{Label: "user"},
+							{Label: "role"},
+							{Label: "group"},
+						},
+					},
+				},
+				ActiveParameter: 2,
+			},
+		},
+		"Should show function signature in the same package": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	is_hello(|)
+}
+
+is_hello(msg) {
+	msg == "hello"
+}`,
+				},
+			},
+			expectResult: &source.SignatureHelp{
+				Signatures: []source.Signature{
+					{
+						Label:      "is_hello(msg)",
+						Parameters: []source.SignatureParameter{{Label: "msg"}},
+					},
+				},
+			},
+		},
+		"Should ignore brackets, commas and quotes in comments": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+f(a, b) = a
+
+violation[msg] {
+	msg := f(
+		"x", # the "first (a, b
+		# )
+		|1
+	)
+}`,
+				},
+			},
+			expectResult: &source.SignatureHelp{
+				Signatures: []source.Signature{
+					{
+						Label:      "f(a, b)",
+						Parameters: []source.SignatureParameter{{Label: "a"}, {Label: "b"}},
+					},
+				},
+				ActiveParameter: 1,
+			},
+		},
+		"Should skip string which ends with escaped backslash": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+f(a, b) = a
+
+violation[msg] {
+	msg := f("(\\", |1)
+}`,
+				},
+			},
+			expectResult: &source.SignatureHelp{
+				Signatures: []source.Signature{
+					{
+						Label:      "f(a, b)",
+						Parameters: []source.SignatureParameter{{Label: "a"}, {Label: "b"}},
+					},
+				},
+				ActiveParameter: 1,
+			},
+		},
+		"Should not show signature out of the call": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	count([]) == |0
+}`,
+				},
+			},
+			expectResult: nil,
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			files, location, err := helper.GetAstLocation(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := source.NewProjectWithFiles(files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := p.SignatureHelp(location)
+			if diff := cmp.Diff(tt.expectResult, got); diff != "" {
+				t.Errorf("SignatureHelp result diff (-expect +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package source
+
+import (
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+type DocumentSymbol struct {
+	Name     string
+	Detail   string
+	Kind     SymbolKind
+	Children []DocumentSymbol
+
+	// Location is the whole range of the symbol.
+	Location *ast.Location
+	// NameLocation is the range of the symbol's name.
+	NameLocation *ast.Location
+}
+
+type SymbolKind int
+
+const (
+	PackageSymbol SymbolKind = iota
+	ImportSymbol
+	RuleSymbol
+	FunctionSymbol
+	DefaultRuleSymbol
+	ElseSymbol
+	TestSymbol
+)
+
+func (p *Project) ListDocumentSymbols(path string) []DocumentSymbol {
+	policy := p.cache.Get(path)
+	if policy == nil || policy.Module == nil {
+		return nil
+	}
+	module := policy.Module
+
+	children := make([]DocumentSymbol, 0, len(module.Imports)+len(module.Rules))
+	for _, imp := range module.Imports {
+		children = append(children, createImportSymbol(imp, policy.RawText))
+	}
+	for _, rules := range groupRulesByName(module.Rules) {
+		children = append(children, createRuleGroupSymbol(rules, policy.RawText))
+	}
+
+	pkg := module.Package
+	last := pkg.Path[len(pkg.Path)-1].Location
+	nameLocation := spanLocation(pkg.Path[1].Location, last, policy.RawText)
+
+	// The range of the package contains all imports and rules, because it is the parent of them.
+	end := last
+	for _, c := range children {
+		if c.Location.Offset+len(c.Location.Text) > end.Offset+len(end.Text) {
+			end = c.Location
+		}
+	}
+	return []DocumentSymbol{
+		{
+			Name:         packageName(pkg),
+			Kind:         PackageSymbol,
+			Children:     children,
+			Location:     spanLocation(pkg.Location, end, policy.RawText),
+			NameLocation: nameLocation,
+		},
+	}
+}
+
+// packageName returns the package path without "data."
+func packageName(pkg *ast.Package) string {
+	return strings.TrimPrefix(pkg.Path.String(), ast.DefaultRootDocument.String()+".")
+}
+
+// groupRulesByName groups the rules which have the same name keeping the order of the first appearance.
+//
+//	default allow = false
+//	allow { ... }
+//	allow { ... }
+func groupRulesByName(rules []*ast.Rule) [][]*ast.Rule {
+	result := make([][]*ast.Rule, 0, len(rules))
+	indexes := make(map[string]int)
+	for _, r := range rules {
+		name := r.Head.Ref().String()
+		if i, ok := indexes[name]; ok {
+			result[i] = append(result[i], r)
+			continue
+		}
+		indexes[name] = len(result)
+		result = append(result, []*ast.Rule{r})
+	}
+	return result
+}
+
+func createImportSymbol(imp *ast.Import, rawText string) DocumentSymbol {
+	// imp.Location only contains "import" keyword.
+	text := rawText[imp.Location.Offset:]
+	if ind := strings.Index(text, "\n"); ind >= 0 {
+		text = text[:ind]
+	}
+	loc := &ast.Location{
+		Row:    imp.Location.Row,
+		Col:    imp.Location.Col,
+		Offset: imp.Location.Offset,
+		File:   imp.Location.File,
+		Text:   []byte(strings.TrimSpace(text)),
+	}
+
+	name := imp.Path.String()
+	if imp.Alias != "" {
+		name += " as " + imp.Alias.String()
+	}
+	return DocumentSymbol{
+		Name:         name,
+		Kind:         ImportSymbol,
+		Location:     loc,
+		NameLocation: imp.Path.Location,
+	}
+}
+
+func createRuleGroupSymbol(rules []*ast.Rule, rawText string) DocumentSymbol {
+	if len(rules) == 1 {
+		return createRuleSymbol(rules[0], rawText)
+	}
+
+	children := make([]DocumentSymbol, len(rules))
+	for i, r := range rules {
+		children[i] = createRuleSymbol(r, rawText)
+	}
+
+	// The kind of the group is decided by the rule which is not default.
+	kind := children[0].Kind
+	for _, c := range children {
+		if c.Kind != DefaultRuleSymbol {
+			kind = c.Kind
+			break
+		}
+	}
+
+	first, last := rules[0], rules[len(rules)-1]
+	return DocumentSymbol{
+		Name:         first.Head.Ref().String(),
+		Kind:         kind,
+		Children:     children,
+		Location:     spanLocation(first.Location, last.Location, rawText),
+		NameLocation: ruleNameLocation(first),
+	}
+}
+
+func createRuleSymbol(rule *ast.Rule, rawText string) DocumentSymbol {
+	var children []DocumentSymbol
+	for e := rule.Else; e != nil; e = e.Else {
+		children = append(children, DocumentSymbol{
+			Name:         "else",
+			Detail:       ruleValueDetail(e),
+			Kind:         ElseSymbol,
+			Location:     e.Location,
+			NameLocation: e.Head.Location,
+		})
+	}
+
+	return DocumentSymbol{
+		Name:         rule.Head.Ref().String(),
+		Detail:       ruleDetail(rule),
+		Kind:         ruleSymbolKind(rule),
+		Children:     children,
+		Location:     ruleLocation(rule, rawText),
+		NameLocation: ruleNameLocation(rule),
+	}
+}
+
+func ruleSymbolKind(rule *ast.Rule) SymbolKind {
+	switch {
+	case rule.Default:
+		return DefaultRuleSymbol
+	case strings.HasPrefix(rule.Head.Ref().String(), "test_"):
+		return TestSymbol
+	case len(rule.Head.Args) > 0:
+		return FunctionSymbol
+	default:
+		return RuleSymbol
+	}
+}
+
+// ruleDetail returns args and the value of the rule.
+//
+//	f(x) = y -> (x) = y
+func ruleDetail(rule *ast.Rule) string {
+	var detail strings.Builder
+	if len(rule.Head.Args) > 0 {
+		detail.WriteString(rule.Head.Args.String())
+	}
+	if rule.Head.Key != nil && rule.Head.Value == nil {
+		detail.WriteString("[" + rule.Head.Key.String() + "]")
+	}
+	if value := ruleValueDetail(rule); value != "" {
+		if detail.Len() > 0 {
+			detail.WriteByte(' ')
+		}
+		detail.WriteString(value)
+	}
+	return detail.String()
+}
+
+func ruleValueDetail(rule *ast.Rule) string {
+	if rule.Head.Value == nil || rule.Head.Value.Location == nil {
+		// the value is generated by the parser. e.g. `allow { ... }` has `true` value.
+		return ""
+	}
+	return "= " + rule.Head.Value.String()
+}
+
+// ruleLocation returns the whole location of the rule.
+// The location of default rule only contains "default" keyword.
+func ruleLocation(rule *ast.Rule, rawText string) *ast.Location {
+	if rule.Default {
+		return spanLocation(rule.Location, rule.Head.Location, rawText)
+	}
+	return rule.Location
+}
+
+// spanLocation returns the location from start to the end of end location.
+func spanLocation(start, end *ast.Location, rawText string) *ast.Location {
+	endOffset := end.Offset + len(end.Text)
+	if endOffset > len(rawText) || start.Offset > endOffset {
+		return start
+	}
+	return &ast.Location{
+		Row:    start.Row,
+		Col:    start.Col,
+		Offset: start.Offset,
+		File:   start.File,
+		Text:   []byte(rawText[start.Offset:endOffset]),
+	}
+}
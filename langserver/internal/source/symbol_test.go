@@ -0,0 +1,142 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+func TestProject_ListDocumentSymbols(t *testing.T) {
+	tests := map[string]struct {
+		files         map[string]source.File
+		path          string
+		expectSymbols []source.DocumentSymbol
+	}{
+		"Should list package, imports and rules": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+import data.lib
+
+f(x) = 1 {
+	x == 1
+} else = 2
+
+test_f {
+	f(1) == 1
+}`,
+				},
+			},
+			path: "src.rego",
+			expectSymbols: []source.DocumentSymbol{
+				{
+					Name: "src",
+					Kind: source.PackageSymbol,
+					Children: []source.DocumentSymbol{
+						{
+							Name:         "data.lib",
+							Kind:         source.ImportSymbol,
+							Location:     &ast.Location{Row: 3, Col: 1, Offset: len("package src\n\n"), Text: []byte("import data.lib"), File: "src.rego"},
+							NameLocation: &ast.Location{Row: 3, Col: 8, Offset: len("package src\n\nimport "), Text: []byte("data.lib"), File: "src.rego"},
+						},
+						{
+							Name:   "f",
+							Detail: "(x) = 1",
+							Kind:   source.FunctionSymbol,
+							Children: []source.DocumentSymbol{
+								{
+									Name:         "else",
+									Detail:       "= 2",
+									Kind:         source.ElseSymbol,
+									Location:     &ast.Location{Row: 7, Col: 3, Offset: len("package src\n\nimport data.lib\n\nf(x) = 1 {\n	x == 1\n} "), Text: []byte("else = 2"), File: "src.rego"},
+									NameLocation: &ast.Location{Row: 7, Col: 3, Offset: len("package src\n\nimport data.lib\n\nf(x) = 1 {\n	x == 1\n} "), Text: []byte("else = 2"), File: "src.rego"},
+								},
+							},
+							Location:     &ast.Location{Row: 5, Col: 1, Offset: len("package src\n\nimport data.lib\n\n"), Text: []byte("f(x) = 1 {\n	x == 1\n} else = 2"), File: "src.rego"},
+							NameLocation: &ast.Location{Row: 5, Col: 1, Offset: len("package src\n\nimport data.lib\n\n"), Text: []byte("f"), File: "src.rego"},
+						},
+						{
+							Name:         "test_f",
+							Kind:         source.TestSymbol,
+							Location:     &ast.Location{Row: 9, Col: 1, Offset: len("package src\n\nimport data.lib\n\nf(x) = 1 {\n	x == 1\n} else = 2\n\n"), Text: []byte("test_f {\n	f(1) == 1\n}"), File: "src.rego"},
+							NameLocation: &ast.Location{Row: 9, Col: 1, Offset: len("package src\n\nimport data.lib\n\nf(x) = 1 {\n	x == 1\n} else = 2\n\n"), Text: []byte("test_f"), File: "src.rego"},
+						},
+					},
+					Location:     &ast.Location{Row: 1, Col: 1, Offset: 0, Text: []byte("package src\n\nimport data.lib\n\nf(x) = 1 {\n	x == 1\n} else = 2\n\ntest_f {\n	f(1) == 1\n}"), File: "src.rego"},
+					NameLocation: &ast.Location{Row: 1, Col: 9, Offset: len("package "), Text: []byte("src"), File: "src.rego"},
+				},
+			},
+		},
+		"Should nest multi-body rules": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+default allow = false
+
+allow {
+	true
+}`,
+				},
+			},
+			path: "src.rego",
+			expectSymbols: []source.DocumentSymbol{
+				{
+					Name: "src",
+					Kind: source.PackageSymbol,
+					Children: []source.DocumentSymbol{
+						{
+							Name: "allow",
+							Kind: source.RuleSymbol,
+							Children: []source.DocumentSymbol{
+								{
+									Name:         "allow",
+									Detail:       "= false",
+									Kind:         source.DefaultRuleSymbol,
+									Location:     &ast.Location{Row: 3, Col: 1, Offset: len("package src\n\n"), Text: []byte("default allow = false"), File: "src.rego"},
+									NameLocation: &ast.Location{Row: 3, Col: 9, Offset: len("package src\n\ndefault "), Text: []byte("allow"), File: "src.rego"},
+								},
+								{
+									Name:         "allow",
+									Kind:         source.RuleSymbol,
+									Location:     &ast.Location{Row: 5, Col: 1, Offset: len("package src\n\ndefault allow = false\n\n"), Text: []byte("allow {\n	true\n}"), File: "src.rego"},
+									NameLocation: &ast.Location{Row: 5, Col: 1, Offset: len("package src\n\ndefault allow = false\n\n"), Text: []byte("allow"), File: "src.rego"},
+								},
+							},
+							Location:     &ast.Location{Row: 3, Col: 1, Offset: len("package src\n\n"), Text: []byte("default allow = false\n\nallow {\n	true\n}"), File: "src.rego"},
+							NameLocation: &ast.Location{Row: 3, Col: 9, Offset: len("package src\n\ndefault "), Text: []byte("allow"), File: "src.rego"},
+						},
+					},
+					Location:     &ast.Location{Row: 1, Col: 1, Offset: 0, Text: []byte("package src\n\ndefault allow = false\n\nallow {\n	true\n}"), File: "src.rego"},
+					NameLocation: &ast.Location{Row: 1, Col: 9, Offset: len("package "), Text: []byte("src"), File: "src.rego"},
+				},
+			},
+		},
+		"Should return nil for unknown file": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src`,
+				},
+			},
+			path:          "unknown.rego",
+			expectSymbols: nil,
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			project, err := source.NewProjectWithFiles(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := project.ListDocumentSymbols(tt.path)
+			if diff := cmp.Diff(tt.expectSymbols, got); diff != "" {
+				t.Errorf("ListDocumentSymbols result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -94,19 +94,20 @@ func (p *Project) searchTargetTermInRule(location *ast.Location, rule *ast.Rule)
 	for rule != nil {
 		if rule.Head != nil {
 			name := &ast.Term{
-				Value: rule.Head.Name,
-				Location: &ast.Location{
-					Row:    rule.Head.Location.Row,
-					Col:    rule.Head.Location.Col,
-					Text:   []byte(rule.Head.Name),
-					Offset: rule.Head.Location.Offset,
-					File:   rule.Head.Location.File,
-				},
+				Value:    ruleName(rule),
+				Location: ruleNameLocation(rule),
 			}
 			if in(location, name.Location) {
 				return name, nil
 			}
 
+			// a.b[x] := 1
+			for _, t := range rule.Head.Ref()[1:] {
+				if _, ok := t.Value.(ast.String); !ok && t.Loc() != nil && in(location, t.Loc()) {
+					return p.searchTargetTermInTerm(location, t)
+				}
+			}
+
 			if rule.Head.Key != nil && rule.Head.Key.Loc() != nil {
 				if in(location, rule.Head.Key.Loc()) {
 					return p.searchTargetTermInTerm(location, rule.Head.Key)
@@ -125,21 +126,67 @@ func (p *Project) searchTargetTermInRule(location *ast.Location, rule *ast.Rule)
 				}
 			}
 		}
-		for _, b := range rule.Body {
-			if !in(location, b.Loc()) {
-				continue
+		term, err := p.searchTargetTermInBody(location, rule.Body)
+		if err != nil || term != nil {
+			return term, err
+		}
+		rule = rule.Else
+	}
+	return nil, nil
+}
+
+func (p *Project) searchTargetTermInBody(location *ast.Location, body ast.Body) (*ast.Term, error) {
+	for _, b := range body {
+		if !in(location, b.Loc()) {
+			continue
+		}
+
+		term, err := p.searchTargetTermInExpr(location, b)
+		if err != nil || term != nil {
+			return term, err
+		}
+
+		// with input.x as y
+		for _, w := range b.With {
+			if in(location, w.Target.Loc()) {
+				return p.searchTargetTermInTerm(location, w.Target)
 			}
+			if in(location, w.Value.Loc()) {
+				return p.searchTargetTermInTerm(location, w.Value)
+			}
+		}
+	}
+	return nil, nil
+}
 
-			switch t := b.Terms.(type) {
-			case *ast.Term:
-				if in(location, t.Loc()) {
-					return p.searchTargetTermInTerm(location, t)
+func (p *Project) searchTargetTermInExpr(location *ast.Location, expr *ast.Expr) (*ast.Term, error) {
+	switch t := expr.Terms.(type) {
+	case *ast.Term:
+		if in(location, t.Loc()) {
+			return p.searchTargetTermInTerm(location, t)
+		}
+	case []*ast.Term:
+		return p.searchTargetTermInTerms(location, t)
+	case *ast.SomeDecl:
+		for _, symbol := range t.Symbols {
+			// The operator of `some x in xs` is generated by the parser.
+			if call, ok := symbol.Value.(ast.Call); ok {
+				term, err := p.searchTargetTermInTerms(location, call[1:])
+				if err != nil || term != nil {
+					return term, err
 				}
-			case []*ast.Term:
-				return p.searchTargetTermInTerms(location, t)
+				continue
+			}
+			if in(location, symbol.Loc()) {
+				return p.searchTargetTermInTerm(location, symbol)
 			}
 		}
-		rule = rule.Else
+	case *ast.Every:
+		term, err := p.searchTargetTermInTerms(location, append(everyVars(t), t.Domain))
+		if err != nil || term != nil {
+			return term, err
+		}
+		return p.searchTargetTermInBody(location, t.Body)
 	}
 	return nil, nil
 }
@@ -194,9 +241,40 @@ func (p *Project) searchTargetTermInTerm(loc *ast.Location, term *ast.Term) (*as
 			}
 		}
 		return nil, nil
+	case ast.Object:
+		for _, key := range v.Keys() {
+			if in(loc, key.Loc()) {
+				return p.searchTargetTermInTerm(loc, key)
+			}
+			value := v.Get(key)
+			if in(loc, value.Loc()) {
+				return p.searchTargetTermInTerm(loc, value)
+			}
+		}
+		return nil, nil
+	case ast.Set:
+		return p.searchTargetTermInTerms(loc, v.Slice())
+	case *ast.ArrayComprehension:
+		if in(loc, v.Term.Loc()) {
+			return p.searchTargetTermInTerm(loc, v.Term)
+		}
+		return p.searchTargetTermInBody(loc, v.Body)
+	case *ast.SetComprehension:
+		if in(loc, v.Term.Loc()) {
+			return p.searchTargetTermInTerm(loc, v.Term)
+		}
+		return p.searchTargetTermInBody(loc, v.Body)
+	case *ast.ObjectComprehension:
+		if in(loc, v.Key.Loc()) {
+			return p.searchTargetTermInTerm(loc, v.Key)
+		}
+		if in(loc, v.Value.Loc()) {
+			return p.searchTargetTermInTerm(loc, v.Value)
+		}
+		return p.searchTargetTermInBody(loc, v.Body)
 	case ast.Var:
 		return term, nil
-	case ast.String, ast.Boolean, ast.Number:
+	case ast.String, ast.Boolean, ast.Number, ast.Null:
 		return nil, nil
 	default:
 		return nil, fmt.Errorf("not supported type %T: %v\n", v, v)
@@ -18,6 +18,8 @@ func TestProject_SearchTargetTerm(t *testing.T) {
 		files      map[string]source.File
 		updateFile map[string]source.File
 		expectTerm *ast.Term
+		// location is used when the cursor can't be written with "|". e.g. comprehension
+		location createLocationFunc
 	}{
 		"Should find term in the body": {
 			files: map[string]source.File{
@@ -313,11 +315,120 @@ messages[msg] {
 				},
 			},
 		},
+		"Should find term in the object": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	msg := {"message": m|sg}
+}`,
+				},
+			},
+			expectTerm: &ast.Term{
+				Value: ast.Var("msg"),
+				Location: &ast.Location{
+					Row:    4,
+					Col:    21,
+					File:   "src.rego",
+					Offset: len("package src\n\nviolation[msg] {\n	msg := {\"message\": "),
+					Text:   []byte("msg"),
+				},
+			},
+		},
+		"Should find term in the set": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	msg := {"a", m|}
+}`,
+				},
+			},
+			expectTerm: &ast.Term{
+				Value: ast.Var("m"),
+				Location: &ast.Location{
+					Row:    4,
+					Col:    15,
+					File:   "src.rego",
+					Offset: len("package src\n\nviolation[msg] {\n	msg := {\"a\", "),
+					Text:   []byte("m"),
+				},
+			},
+		},
+		"Should find term in the comprehension body": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	msg := [x | x := input.a[_]; x > 1]
+}`,
+				},
+			},
+			location: createLocation(4, 31, "src.rego"),
+			expectTerm: &ast.Term{
+				Value: ast.Var("x"),
+				Location: &ast.Location{
+					Row:    4,
+					Col:    31,
+					File:   "src.rego",
+					Offset: len("package src\n\nviolation[msg] {\n	msg := [x | x := input.a[_]; "),
+					Text:   []byte("x"),
+				},
+			},
+		},
+		"Should find term in the object comprehension key": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+violation[msg] {
+	msg := {k|: v | some k; v := input[k]}
+}`,
+				},
+			},
+			expectTerm: &ast.Term{
+				Value: ast.Var("k"),
+				Location: &ast.Location{
+					Row:    4,
+					Col:    10,
+					File:   "src.rego",
+					Offset: len("package src\n\nviolation[msg] {\n	msg := {"),
+					Text:   []byte("k"),
+				},
+			},
+		},
+		"Should find term in the with value": {
+			files: map[string]source.File{
+				"src.rego": {
+					RawText: `package src
+
+test_allow {
+	allow with input as mock_in|put
+}`,
+				},
+			},
+			expectTerm: &ast.Term{
+				Value: ast.Var("mock_input"),
+				Location: &ast.Location{
+					Row:    4,
+					Col:    22,
+					File:   "src.rego",
+					Offset: len("package src\n\ntest_allow {\n	allow with input as "),
+					Text:   []byte("mock_input"),
+				},
+			},
+		},
 	}
 
 	for n, tt := range tests {
 		t.Run(n, func(t *testing.T) {
 			files, location, err := helper.GetAstLocation(tt.files)
+			if tt.location != nil {
+				files, location, err = tt.files, tt.location(tt.files), nil
+			}
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,125 @@
+package source
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+type WorkspaceSymbol struct {
+	Name string
+	Kind SymbolKind
+	// ContainerName is the package path of the symbol. e.g. data.lib
+	ContainerName string
+	Location      *ast.Location
+}
+
+// ListWorkspaceSymbols lists the packages and the rules which match the query.
+// The query is matched with the name and the full path(e.g. data.lib.rule) fuzzily.
+func (p *Project) ListWorkspaceSymbols(query string) []WorkspaceSymbol {
+	type scoredSymbol struct {
+		symbol WorkspaceSymbol
+		score  int
+	}
+
+	query = strings.ToLower(query)
+	candidates := make([]scoredSymbol, 0)
+	add := func(symbol WorkspaceSymbol, fullPath string) {
+		score := max(fuzzyMatchScore(query, symbol.Name), fuzzyMatchScore(query, fullPath))
+		if score < 0 {
+			return
+		}
+		candidates = append(candidates, scoredSymbol{symbol: symbol, score: score})
+	}
+
+	// The package which is split across files is listed once at the first file.
+	pkgLocations := make(map[string]*ast.Location)
+	for _, module := range p.cache.GetModules() {
+		pkg := module.Package
+		pkgPath := pkg.Path.String()
+		loc := pkg.Path[len(pkg.Path)-1].Location
+		if l, ok := pkgLocations[pkgPath]; !ok || loc.File < l.File {
+			pkgLocations[pkgPath] = loc
+		}
+
+		for _, rule := range module.Rules {
+			name := rule.Head.Ref().String()
+			add(WorkspaceSymbol{
+				Name:          name,
+				Kind:          ruleSymbolKind(rule),
+				ContainerName: pkgPath,
+				Location:      ruleNameLocation(rule),
+			}, pkgPath+"."+name)
+		}
+	}
+
+	for pkgPath, loc := range pkgLocations {
+		add(WorkspaceSymbol{
+			Name:     pkgPath,
+			Kind:     PackageSymbol,
+			Location: loc,
+		}, pkgPath)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		a, b := candidates[i].symbol, candidates[j].symbol
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Location.File != b.Location.File {
+			return a.Location.File < b.Location.File
+		}
+		return a.Location.Offset < b.Location.Offset
+	})
+
+	result := make([]WorkspaceSymbol, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.symbol
+	}
+	return result
+}
+
+// fuzzyMatchScore returns the score how the target matches the query.
+// The query should be lower case. If the target doesn't match, it returns -1.
+//
+//	exact match > prefix match > substring match > subsequence match
+func fuzzyMatchScore(query, target string) int {
+	target = strings.ToLower(target)
+	switch {
+	case query == "":
+		return 0
+	case target == query:
+		return 300
+	case strings.HasPrefix(target, query):
+		return 200
+	case strings.HasSuffix(target, "."+query):
+		// data.lib.rule matches with rule
+		return 150
+	case strings.Contains(target, query):
+		return 100
+	}
+
+	// subsequence match. The score decreases as the gap between characters increases.
+	score := 50
+	queryInd := 0
+	gap := 0
+	for i := 0; i < len(target) && queryInd < len(query); i++ {
+		if target[i] == query[queryInd] {
+			queryInd++
+			score -= gap
+			gap = 0
+			continue
+		}
+		if queryInd > 0 {
+			gap++
+		}
+	}
+	if queryInd < len(query) {
+		return -1
+	}
+	return max(score, 1)
+}
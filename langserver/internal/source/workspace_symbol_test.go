@@ -0,0 +1,151 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+func TestProject_ListWorkspaceSymbols(t *testing.T) {
+	files := map[string]source.File{
+		"src.rego": {
+			RawText: `package src
+
+violation[msg] {
+	msg := "hello"
+}`,
+		},
+		"src/deny.rego": {
+			RawText: `package src
+
+deny[msg] {
+	msg := "denied"
+}`,
+		},
+		"lib/lib.rego": {
+			RawText: `package lib.util
+
+is_admin(user) {
+	user == "admin"
+}
+
+is_member(user) {
+	user == "member"
+}`,
+		},
+	}
+
+	tests := map[string]struct {
+		query         string
+		expectSymbols []source.WorkspaceSymbol
+	}{
+		"Should list rules which have the prefix": {
+			query: "is_ad",
+			expectSymbols: []source.WorkspaceSymbol{
+				{
+					Name:          "is_admin",
+					Kind:          source.FunctionSymbol,
+					ContainerName: "data.lib.util",
+					Location:      &ast.Location{Row: 3, Col: 1, Offset: len("package lib.util\n\n"), Text: []byte("is_admin"), File: "lib/lib.rego"},
+				},
+			},
+		},
+		"Should list rules fuzzily": {
+			query: "ismem",
+			expectSymbols: []source.WorkspaceSymbol{
+				{
+					Name:          "is_member",
+					Kind:          source.FunctionSymbol,
+					ContainerName: "data.lib.util",
+					Location:      &ast.Location{Row: 7, Col: 1, Offset: len("package lib.util\n\nis_admin(user) {\n	user == \"admin\"\n}\n\n"), Text: []byte("is_member"), File: "lib/lib.rego"},
+				},
+			},
+		},
+		"Should list rules with full path": {
+			query: "data.src.vio",
+			expectSymbols: []source.WorkspaceSymbol{
+				{
+					Name:          "violation",
+					Kind:          source.RuleSymbol,
+					ContainerName: "data.src",
+					Location:      &ast.Location{Row: 3, Col: 1, Offset: len("package src\n\n"), Text: []byte("violation"), File: "src.rego"},
+				},
+			},
+		},
+		"Should list package and its rules": {
+			query: "util",
+			expectSymbols: []source.WorkspaceSymbol{
+				{
+					Name:     "data.lib.util",
+					Kind:     source.PackageSymbol,
+					Location: &ast.Location{Row: 1, Col: 13, Offset: len("package lib."), Text: []byte("util"), File: "lib/lib.rego"},
+				},
+				{
+					Name:          "is_admin",
+					Kind:          source.FunctionSymbol,
+					ContainerName: "data.lib.util",
+					Location:      &ast.Location{Row: 3, Col: 1, Offset: len("package lib.util\n\n"), Text: []byte("is_admin"), File: "lib/lib.rego"},
+				},
+				{
+					Name:          "is_member",
+					Kind:          source.FunctionSymbol,
+					ContainerName: "data.lib.util",
+					Location:      &ast.Location{Row: 7, Col: 1, Offset: len("package lib.util\n\nis_admin(user) {\n	user == \"admin\"\n}\n\n"), Text: []byte("is_member"), File: "lib/lib.rego"},
+				},
+			},
+		},
+		"Should list package which is split across files once": {
+			query: "data.src",
+			expectSymbols: []source.WorkspaceSymbol{
+				{
+					Name:     "data.src",
+					Kind:     source.PackageSymbol,
+					Location: &ast.Location{Row: 1, Col: 9, Offset: len("package "), Text: []byte("src"), File: "src.rego"},
+				},
+				{
+					Name:          "deny",
+					Kind:          source.RuleSymbol,
+					ContainerName: "data.src",
+					Location:      &ast.Location{Row: 3, Col: 1, Offset: len("package src\n\n"), Text: []byte("deny"), File: "src/deny.rego"},
+				},
+				{
+					Name:          "violation",
+					Kind:          source.RuleSymbol,
+					ContainerName: "data.src",
+					Location:      &ast.Location{Row: 3, Col: 1, Offset: len("package src\n\n"), Text: []byte("violation"), File: "src.rego"},
+				},
+			},
+		},
+		"Should sort exact match first": {
+			query: "is_member",
+			expectSymbols: []source.WorkspaceSymbol{
+				{
+					Name:          "is_member",
+					Kind:          source.FunctionSymbol,
+					ContainerName: "data.lib.util",
+					Location:      &ast.Location{Row: 7, Col: 1, Offset: len("package lib.util\n\nis_admin(user) {\n	user == \"admin\"\n}\n\n"), Text: []byte("is_member"), File: "lib/lib.rego"},
+				},
+			},
+		},
+		"Should return nothing when nothing matches": {
+			query:         "xyz",
+			expectSymbols: []source.WorkspaceSymbol{},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			project, err := source.NewProjectWithFiles(files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := project.ListWorkspaceSymbols(tt.query)
+			if diff := cmp.Diff(tt.expectSymbols, got); diff != "" {
+				t.Errorf("ListWorkspaceSymbols result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/kitagry/regols/langserver/internal/lsp"
 	"github.com/kitagry/regols/langserver/internal/source"
@@ -15,6 +16,10 @@ type handler struct {
 	conn   *jsonrpc2.Conn
 	logger *log.Logger
 
+	// mu guards config, because workspace/configuration is handled in the goroutine.
+	mu     sync.Mutex
+	config config
+
 	diagnosticRequest chan lsp.DocumentURI
 	initializeParams  lsp.InitializeParams
 
@@ -35,7 +40,7 @@ func (h *handler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2
 	case "initialize":
 		return h.handleInitialize(ctx, conn, req)
 	case "initialized":
-		return
+		return h.handleInitialized(ctx, conn, req)
 	case "textDocument/didOpen":
 		return h.handleTextDocumentDidOpen(ctx, conn, req)
 	case "textDocument/didChange":
@@ -50,10 +55,42 @@ func (h *handler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2
 		return h.handleTextDocumentDefinition(ctx, conn, req)
 	case "textDocument/completion":
 		return h.handleTextDocumentCompletion(ctx, conn, req)
+	case "completionItem/resolve":
+		return h.handleCompletionItemResolve(ctx, conn, req)
 	case "textDocument/hover":
 		return h.handleTextDocumentHover(ctx, conn, req)
 	case "textDocument/references":
 		return h.handleTextDocumentReferences(ctx, conn, req)
+	case "textDocument/documentHighlight":
+		return h.handleTextDocumentDocumentHighlight(ctx, conn, req)
+	case "textDocument/documentSymbol":
+		return h.handleTextDocumentDocumentSymbol(ctx, conn, req)
+	case "textDocument/signatureHelp":
+		return h.handleTextDocumentSignatureHelp(ctx, conn, req)
+	case "textDocument/foldingRange":
+		return h.handleTextDocumentFoldingRange(ctx, conn, req)
+	case "textDocument/semanticTokens/full":
+		return h.handleTextDocumentSemanticTokensFull(ctx, conn, req)
+	case "textDocument/semanticTokens/range":
+		return h.handleTextDocumentSemanticTokensRange(ctx, conn, req)
+	case "textDocument/codeAction":
+		return h.handleTextDocumentCodeAction(ctx, conn, req)
+	case "workspace/didChangeConfiguration":
+		return h.handleWorkspaceDidChangeConfiguration(ctx, conn, req)
+	case "workspace/didChangeWatchedFiles":
+		return h.handleWorkspaceDidChangeWatchedFiles(ctx, conn, req)
+	case "workspace/didChangeWorkspaceFolders":
+		return h.handleWorkspaceDidChangeWorkspaceFolders(ctx, conn, req)
+	case "workspace/didCreateFiles":
+		return h.handleWorkspaceDidCreateFiles(ctx, conn, req)
+	case "workspace/didDeleteFiles":
+		return h.handleWorkspaceDidDeleteFiles(ctx, conn, req)
+	case "workspace/symbol":
+		return h.handleWorkspaceSymbol(ctx, conn, req)
+	case "textDocument/prepareRename":
+		return h.handleTextDocumentPrepareRename(ctx, conn, req)
+	case "textDocument/rename":
+		return h.handleTextDocumentRename(ctx, conn, req)
 	}
 	return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
 }
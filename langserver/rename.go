@@ -0,0 +1,65 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func (h *handler) handleTextDocumentPrepareRename(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.TextDocumentPositionParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	loc := h.toOPALocation(params.Position, params.TextDocument.URI)
+	target, err := h.project.PrepareRename(loc)
+	if err != nil {
+		h.logger.Printf("failed to prepare rename: %v", err)
+		return nil, nil
+	}
+
+	return toLspRange(target), nil
+}
+
+func (h *handler) handleTextDocumentRename(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.RenameParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	loc := h.toOPALocation(params.Position, params.TextDocument.URI)
+	locations, err := h.project.Rename(loc, params.NewName)
+	if err != nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidRequest, Message: err.Error()}
+	}
+
+	changes := make(map[string][]lsp.TextEdit)
+	for _, l := range locations {
+		newText := params.NewName
+		// data["lib"]["rule"]
+		if strings.HasPrefix(string(l.Text), `"`) {
+			newText = strconv.Quote(params.NewName)
+		}
+
+		uri := string(uriToDocumentURI(l.File))
+		changes[uri] = append(changes[uri], lsp.TextEdit{
+			Range:   toLspRange(l),
+			NewText: newText,
+		})
+	}
+
+	return lsp.WorkspaceEdit{Changes: changes}, nil
+}
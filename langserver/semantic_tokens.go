@@ -0,0 +1,95 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// semanticTokenTypes is the legend of the token types. The index is source.SemanticTokenType.
+var semanticTokenTypes = []string{
+	source.NamespaceToken: "namespace",
+	source.RuleToken:      "property",
+	source.FunctionToken:  "function",
+	source.ParameterToken: "parameter",
+	source.VariableToken:  "variable",
+}
+
+// semanticTokenModifiers is the legend of the token modifiers. The index is the bit of source.SemanticTokenModifier.
+var semanticTokenModifiers = []string{
+	"declaration",
+	"defaultLibrary",
+}
+
+func semanticTokensProvider() *lsp.SemanticTokensOptions {
+	return &lsp.SemanticTokensOptions{
+		Legend: lsp.SemanticTokensLegend{
+			TokenTypes:     semanticTokenTypes,
+			TokenModifiers: semanticTokenModifiers,
+		},
+		Range: true,
+		Full:  true,
+	}
+}
+
+func (h *handler) handleTextDocumentSemanticTokensFull(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.SemanticTokensParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	tokens := h.project.ListSemanticTokens(documentURIToURI(params.TextDocument.URI))
+	return encodeSemanticTokens(tokens), nil
+}
+
+func (h *handler) handleTextDocumentSemanticTokensRange(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.SemanticTokensRangeParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	tokens := h.project.ListSemanticTokens(documentURIToURI(params.TextDocument.URI))
+	filtered := make([]source.SemanticToken, 0, len(tokens))
+	for _, t := range tokens {
+		line := t.Location.Row - 1
+		if line < params.Range.Start.Line || line > params.Range.End.Line {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return encodeSemanticTokens(filtered), nil
+}
+
+// encodeSemanticTokens encodes the tokens to the relative positions.
+// The tokens should be sorted by the position.
+func encodeSemanticTokens(tokens []source.SemanticToken) lsp.SemanticTokens {
+	data := make([]uint32, 0, len(tokens)*5)
+	prevLine, prevChar := 0, 0
+	for _, t := range tokens {
+		line, char := t.Location.Row-1, t.Location.Col-1
+		deltaChar := char
+		if line == prevLine {
+			deltaChar = char - prevChar
+		}
+		data = append(data,
+			uint32(line-prevLine),
+			uint32(deltaChar),
+			uint32(len(t.Location.Text)),
+			uint32(t.Type),
+			uint32(t.Modifiers),
+		)
+		prevLine, prevChar = line, char
+	}
+	return lsp.SemanticTokens{Data: data}
+}
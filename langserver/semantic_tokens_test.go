@@ -0,0 +1,42 @@
+package langserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+func TestEncodeSemanticTokens(t *testing.T) {
+	tokens := []source.SemanticToken{
+		{
+			Location: &ast.Location{Row: 1, Col: 9, Text: []byte("src")},
+			Type:     source.NamespaceToken,
+		},
+		{
+			Location:  &ast.Location{Row: 3, Col: 1, Text: []byte("allow")},
+			Type:      source.RuleToken,
+			Modifiers: source.DeclarationModifier,
+		},
+		{
+			Location:  &ast.Location{Row: 3, Col: 9, Text: []byte("input")},
+			Type:      source.VariableToken,
+			Modifiers: source.DefaultLibraryModifier,
+		},
+	}
+
+	expect := lsp.SemanticTokens{
+		Data: []uint32{
+			0, 8, 3, uint32(source.NamespaceToken), 0,
+			2, 0, 5, uint32(source.RuleToken), uint32(source.DeclarationModifier),
+			0, 8, 5, uint32(source.VariableToken), uint32(source.DefaultLibraryModifier),
+		},
+	}
+
+	got := encodeSemanticTokens(tokens)
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("encodeSemanticTokens result diff (-expect +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,47 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func (h *handler) handleTextDocumentSignatureHelp(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.TextDocumentPositionParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	loc := h.toOPALocation(params.Position, params.TextDocument.URI)
+	help := h.project.SignatureHelp(loc)
+	if help == nil {
+		return nil, nil
+	}
+
+	signatures := make([]lsp.SignatureInformation, len(help.Signatures))
+	for i, s := range help.Signatures {
+		parameters := make([]lsp.ParameterInformation, len(s.Parameters))
+		for j, param := range s.Parameters {
+			parameters[j] = lsp.ParameterInformation{
+				Label:         param.Label,
+				Documentation: param.Documentation,
+			}
+		}
+		signatures[i] = lsp.SignatureInformation{
+			Label:         s.Label,
+			Documentation: createDocumentation(s.Documentation),
+			Parameters:    parameters,
+		}
+	}
+
+	return lsp.SignatureHelp{
+		Signatures:      signatures,
+		ActiveParameter: help.ActiveParameter,
+	}, nil
+}
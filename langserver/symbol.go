@@ -0,0 +1,111 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func (h *handler) handleTextDocumentDocumentSymbol(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.DocumentSymbolParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	symbols := h.project.ListDocumentSymbols(documentURIToURI(params.TextDocument.URI))
+	if h.clientSupportHierarchicalDocumentSymbol() {
+		return toLspDocumentSymbols(symbols), nil
+	}
+	return toLspSymbolInformations(params.TextDocument.URI, symbols, ""), nil
+}
+
+func (h *handler) clientSupportHierarchicalDocumentSymbol() bool {
+	return h.initializeParams.Capabilities.TextDocument.DocumentSymbol.HierarchicalDocumentSymbolSupport
+}
+
+func toLspDocumentSymbols(symbols []source.DocumentSymbol) []lsp.DocumentSymbol {
+	result := make([]lsp.DocumentSymbol, len(symbols))
+	for i, s := range symbols {
+		result[i] = lsp.DocumentSymbol{
+			Name:           s.Name,
+			Detail:         s.Detail,
+			Kind:           symbolKindToLspKind(s.Kind),
+			Range:          toLspRange(s.Location),
+			SelectionRange: toLspRange(s.NameLocation),
+			Children:       toLspDocumentSymbols(s.Children),
+		}
+	}
+	return result
+}
+
+// toLspSymbolInformations flattens the symbols for the client which doesn't support hierarchical document symbols.
+func toLspSymbolInformations(uri lsp.DocumentURI, symbols []source.DocumentSymbol, containerName string) []lsp.SymbolInformation {
+	result := make([]lsp.SymbolInformation, 0, len(symbols))
+	for _, s := range symbols {
+		result = append(result, lsp.SymbolInformation{
+			Name: s.Name,
+			Kind: symbolKindToLspKind(s.Kind),
+			Location: lsp.Location{
+				URI:   uri,
+				Range: toLspRange(s.Location),
+			},
+			ContainerName: containerName,
+		})
+		result = append(result, toLspSymbolInformations(uri, s.Children, s.Name)...)
+	}
+	return result
+}
+
+func symbolKindToLspKind(kind source.SymbolKind) lsp.SymbolKind {
+	switch kind {
+	case source.PackageSymbol:
+		return lsp.SKPackage
+	case source.ImportSymbol:
+		return lsp.SKModule
+	case source.FunctionSymbol:
+		return lsp.SKFunction
+	case source.DefaultRuleSymbol:
+		return lsp.SKConstant
+	case source.TestSymbol:
+		return lsp.SKMethod
+	default:
+		return lsp.SKVariable
+	}
+}
+
+func (h *handler) handleWorkspaceSymbol(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.WorkspaceSymbolParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	symbols := h.project.ListWorkspaceSymbols(params.Query)
+	if params.Limit > 0 && len(symbols) > params.Limit {
+		symbols = symbols[:params.Limit]
+	}
+
+	informations := make([]lsp.SymbolInformation, len(symbols))
+	for i, s := range symbols {
+		informations[i] = lsp.SymbolInformation{
+			Name: s.Name,
+			Kind: symbolKindToLspKind(s.Kind),
+			Location: lsp.Location{
+				URI:   uriToDocumentURI(s.Location.File),
+				Range: toLspRange(s.Location),
+			},
+			ContainerName: s.ContainerName,
+		}
+	}
+	return informations, nil
+}
@@ -3,6 +3,8 @@ package langserver
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/kitagry/regols/langserver/internal/lsp"
 	"github.com/sourcegraph/jsonrpc2"
@@ -33,11 +35,90 @@ func (h *handler) handleTextDocumentDidChange(_ context.Context, _ *jsonrpc2.Con
 		return nil, err
 	}
 
-	h.updateDocument(params.TextDocument.URI, params.ContentChanges[0].Text, params.TextDocument.Version)
+	path := documentURIToURI(params.TextDocument.URI)
+	rawText, ok := h.project.GetFile(path)
+	if !ok {
+		return nil, fmt.Errorf("failed to find document %s", params.TextDocument.URI)
+	}
+
+	// Drop the stale change. The version of the document should be increased after each change.
+	if version, ok := h.project.GetFileVersion(path); ok && params.TextDocument.Version <= version {
+		h.logger.Printf("ignore stale change of %s: version %d <= %d", params.TextDocument.URI, params.TextDocument.Version, version)
+		return nil, nil
+	}
+
+	text, err := applyContentChanges(rawText, params.ContentChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.getConfig().DiagnosticsTrigger == diagnosticsOnSave {
+		// The diagnostics run when the document is saved.
+		return nil, h.project.UpdateFile(path, text, params.TextDocument.Version)
+	}
+	h.updateDocument(params.TextDocument.URI, text, params.TextDocument.Version)
 
 	return nil, nil
 }
 
+// applyContentChanges applies the changes to the text in order.
+// When the change doesn't have the range, the change is the whole document.
+func applyContentChanges(text string, changes []lsp.TextDocumentContentChangeEvent) (string, error) {
+	for _, c := range changes {
+		if c.Range == nil {
+			text = c.Text
+			continue
+		}
+
+		start, err := positionToOffset(text, c.Range.Start)
+		if err != nil {
+			return "", err
+		}
+		end, err := positionToOffset(text, c.Range.End)
+		if err != nil {
+			return "", err
+		}
+		if start > end {
+			return "", fmt.Errorf("invalid range %s", c.Range)
+		}
+		text = text[:start] + c.Text + text[end:]
+	}
+	return text, nil
+}
+
+// positionToOffset converts the position to the byte offset of the text.
+// The character of the position is counted in UTF-16 code units.
+func positionToOffset(text string, position lsp.Position) (int, error) {
+	offset := 0
+	for i := 0; i < position.Line; i++ {
+		ind := strings.Index(text[offset:], "\n")
+		if ind == -1 {
+			return 0, fmt.Errorf("line %d is out of range", position.Line)
+		}
+		offset += ind + 1
+	}
+
+	line := text[offset:]
+	if ind := strings.Index(line, "\n"); ind >= 0 {
+		line = line[:ind]
+	}
+
+	units := 0
+	for i, r := range line {
+		if units >= position.Character {
+			return offset + i, nil
+		}
+		if r >= 0x10000 {
+			// surrogate pair
+			units += 2
+		} else {
+			units++
+		}
+	}
+	// The position which is over the line length is the end of the line.
+	return offset + len(line), nil
+}
+
 func (h *handler) handleTextDocumentDidClose(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
 	if req.Params == nil {
 		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
@@ -48,7 +129,12 @@ func (h *handler) handleTextDocumentDidClose(_ context.Context, _ *jsonrpc2.Conn
 		return nil, err
 	}
 
-	h.project.DeleteFile(documentURIToURI(params.TextDocument.URI))
+	if err := h.project.CloseFile(documentURIToURI(params.TextDocument.URI)); err != nil {
+		return nil, err
+	}
+
+	// clear the diagnostics of the closed document, and re-run diagnostics for the files which depend on it.
+	go h.diagnoseDependents(context.Background(), params.TextDocument.URI)
 
 	return nil, nil
 }
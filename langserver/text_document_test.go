@@ -0,0 +1,81 @@
+package langserver
+
+import (
+	"testing"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+)
+
+func TestApplyContentChanges(t *testing.T) {
+	tests := map[string]struct {
+		text    string
+		changes []lsp.TextDocumentContentChangeEvent
+		expect  string
+	}{
+		"full change": {
+			text: "package src",
+			changes: []lsp.TextDocumentContentChangeEvent{
+				{Text: "package lib"},
+			},
+			expect: "package lib",
+		},
+		"insert text": {
+			text: "package src\n\nallow {\n\ttrue\n}",
+			changes: []lsp.TextDocumentContentChangeEvent{
+				{
+					Range: &lsp.Range{
+						Start: lsp.Position{Line: 3, Character: 5},
+						End:   lsp.Position{Line: 3, Character: 5},
+					},
+					Text: "\n\tfalse",
+				},
+			},
+			expect: "package src\n\nallow {\n\ttrue\n\tfalse\n}",
+		},
+		"apply changes in order": {
+			text: "package src\n\nallow {\n\ttrue\n}",
+			changes: []lsp.TextDocumentContentChangeEvent{
+				{
+					Range: &lsp.Range{
+						Start: lsp.Position{Line: 2, Character: 0},
+						End:   lsp.Position{Line: 2, Character: 5},
+					},
+					Text: "deny",
+				},
+				{
+					Range: &lsp.Range{
+						Start: lsp.Position{Line: 2, Character: 4},
+						End:   lsp.Position{Line: 4, Character: 1},
+					},
+					Text: " = true",
+				},
+			},
+			expect: "package src\n\ndeny = true",
+		},
+		"count character as UTF-16 code units": {
+			text: "msg := \"🍣あ\"",
+			changes: []lsp.TextDocumentContentChangeEvent{
+				{
+					Range: &lsp.Range{
+						Start: lsp.Position{Line: 0, Character: 10},
+						End:   lsp.Position{Line: 0, Character: 11},
+					},
+					Text: "い",
+				},
+			},
+			expect: "msg := \"🍣い\"",
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			got, err := applyContentChanges(tt.text, tt.changes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expect {
+				t.Errorf("applyContentChanges expect %q, but got %q", tt.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kitagry/regols/langserver/internal/lsp"
+	"github.com/kitagry/regols/langserver/internal/source"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+const (
+	regoFileGlob   = "**/*.rego"
+	configFileGlob = "**/" + source.ConfigFileName
+	ignoreFileGlob = "**/{.gitignore,.regolsignore}"
+	dataFileGlob   = "**/data.{json,yaml,yml}"
+	// folderGlob matches the deleted directory, because the rego file globs don't match it.
+	folderGlob = "**/*"
+)
+
+func (h *handler) handleInitialized(ctx context.Context, conn *jsonrpc2.Conn, _ *jsonrpc2.Request) (result any, err error) {
+	go h.fetchConfig(context.Background(), conn)
+
+	watchedFiles := h.initializeParams.Capabilities.Workspace.DidChangeWatchedFiles
+	if watchedFiles == nil || !watchedFiles.DynamicRegistration {
+		return nil, nil
+	}
+
+	params := lsp.RegistrationParams{
+		Registrations: []lsp.Registration{
+			{
+				ID:     "workspace/didChangeWatchedFiles",
+				Method: "workspace/didChangeWatchedFiles",
+				RegisterOptions: lsp.DidChangeWatchedFilesRegistrationOptions{
+					Watchers: []lsp.FileSystemWatcher{
+						{GlobPattern: regoFileGlob},
+						{GlobPattern: configFileGlob},
+						{GlobPattern: ignoreFileGlob},
+						{GlobPattern: dataFileGlob},
+						{GlobPattern: folderGlob, Kind: lsp.WatchDelete},
+					},
+				},
+			},
+		},
+	}
+	// Don't wait the response, because the handler cannot receive it until this request finishes.
+	if _, err := conn.DispatchCall(ctx, "client/registerCapability", params); err != nil {
+		h.logger.Printf("failed to register didChangeWatchedFiles: %v", err)
+	}
+	return nil, nil
+}
+
+func (h *handler) handleWorkspaceDidChangeWatchedFiles(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.DidChangeWatchedFilesParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	// The diagnostics compile the whole project, so they run once for all changes.
+	var diagnosticURI lsp.DocumentURI
+	for _, c := range params.Changes {
+		if isConfigFile(c.URI) {
+			if h.reloadConfig(ctx, c.URI) {
+				diagnosticURI = c.URI
+			}
+			continue
+		}
+
+		switch lsp.FileChangeType(c.Type) {
+		case lsp.Created, lsp.Changed:
+			if h.loadFile(c.URI) {
+				diagnosticURI = c.URI
+			}
+		case lsp.Deleted:
+			if h.deleteFile(ctx, c.URI) {
+				diagnosticURI = c.URI
+			}
+		}
+	}
+	if diagnosticURI != "" {
+		h.diagnosticRequest <- diagnosticURI
+	}
+	return nil, nil
+}
+
+func (h *handler) handleWorkspaceDidCreateFiles(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.CreateFilesParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	var diagnosticURI lsp.DocumentURI
+	for _, f := range params.Files {
+		if h.loadFile(f.URI) {
+			diagnosticURI = f.URI
+		}
+	}
+	if diagnosticURI != "" {
+		h.diagnosticRequest <- diagnosticURI
+	}
+	return nil, nil
+}
+
+func (h *handler) handleWorkspaceDidDeleteFiles(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.DeleteFilesParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	var diagnosticURI lsp.DocumentURI
+	for _, f := range params.Files {
+		if h.deleteFile(ctx, f.URI) {
+			diagnosticURI = f.URI
+		}
+	}
+	if diagnosticURI != "" {
+		h.diagnosticRequest <- diagnosticURI
+	}
+	return nil, nil
+}
+
+func (h *handler) handleWorkspaceDidChangeWorkspaceFolders(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.DidChangeWorkspaceFoldersParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	for _, f := range params.Event.Removed {
+		removed := h.project.RemoveRootPath(documentURIToURI(f.URI))
+		h.clearDiagnostics(ctx, removed)
+		if len(removed) > 0 {
+			h.diagnosticRequest <- f.URI
+		}
+	}
+
+	for _, f := range params.Event.Added {
+		if err := h.project.AddRootPath(documentURIToURI(f.URI)); err != nil && !h.showConfigError(ctx, err) {
+			h.logger.Printf("failed to add workspace folder %s: %v", f.URI, err)
+			continue
+		}
+		h.diagnosticRequest <- f.URI
+	}
+	return nil, nil
+}
+
+func fileOperationsCapabilities() *lsp.FileOperationsServerCapabilities {
+	filters := []lsp.FileOperationFilter{
+		{Scheme: "file", Pattern: lsp.FileOperationPattern{Glob: regoFileGlob, Matches: lsp.FOPKFile}},
+		{Scheme: "file", Pattern: lsp.FileOperationPattern{Glob: dataFileGlob, Matches: lsp.FOPKFile}},
+	}
+	return &lsp.FileOperationsServerCapabilities{
+		DidCreate: &lsp.FileOperationRegistrationOptions{Filters: filters},
+		DidDelete: &lsp.FileOperationRegistrationOptions{
+			Filters: append(filters, lsp.FileOperationFilter{
+				Scheme:  "file",
+				Pattern: lsp.FileOperationPattern{Glob: folderGlob, Matches: lsp.FOPKFolder},
+			}),
+		},
+	}
+}
+
+// loadFile reads the file which is changed outside of the client.
+// When the file is opened, the opened document still shadows it.
+// It returns true when the diagnostics should run again.
+func (h *handler) loadFile(uri lsp.DocumentURI) bool {
+	if !isRegoFile(uri) && !isDataFile(uri) {
+		return false
+	}
+
+	if err := h.project.LoadFile(documentURIToURI(uri)); err != nil {
+		h.logger.Printf("failed to load %s: %v", uri, err)
+		return false
+	}
+	// The data document doesn't have the diagnostics.
+	return isRegoFile(uri)
+}
+
+// deleteFile deletes the file or the directory, and clears the diagnostics of them.
+// It returns true when the diagnostics should run again for the files which depend on the deleted files.
+func (h *handler) deleteFile(ctx context.Context, uri lsp.DocumentURI) bool {
+	path := documentURIToURI(uri)
+	if isDataFile(uri) {
+		h.project.DeleteFile(path)
+		return false
+	}
+
+	deleted := []string{path}
+	if isRegoFile(uri) {
+		h.project.DeleteFile(path)
+	} else {
+		deleted = h.project.DeleteDir(path)
+	}
+
+	h.clearDiagnostics(ctx, deleted)
+	return len(deleted) > 0
+}
+
+// clearDiagnostics clears the diagnostics of the files which are removed from the project.
+func (h *handler) clearDiagnostics(ctx context.Context, paths []string) {
+	for _, p := range paths {
+		// The opened document is kept until it is closed.
+		if h.project.IsOpen(p) {
+			continue
+		}
+		h.conn.Notify(ctx, "textDocument/publishDiagnostics", lsp.PublishDiagnosticsParams{
+			URI:         uriToDocumentURI(p),
+			Diagnostics: []lsp.Diagnostic{},
+		})
+	}
+}
+
+// reloadConfig reloads the configuration file or the ignore file of the workspace folder.
+// It returns true when the diagnostics should run again.
+func (h *handler) reloadConfig(ctx context.Context, uri lsp.DocumentURI) bool {
+	root, ok := h.rootPathOf(uri)
+	if !ok {
+		return false
+	}
+
+	removed, err := h.project.ReloadConfig(root)
+	if err != nil && !h.showConfigError(ctx, err) {
+		h.logger.Printf("failed to reload %s: %v", uri, err)
+		return false
+	}
+	h.clearDiagnostics(ctx, removed)
+	return true
+}
+
+// rootPathOf returns the workspace folder of the configuration file or the ignore file.
+// The configuration file is read only in the workspace folder, but the ignore file is read in any directory.
+func (h *handler) rootPathOf(uri lsp.DocumentURI) (string, bool) {
+	p := documentURIToURI(uri)
+	for _, r := range h.project.RootPaths() {
+		if path.Base(p) == source.ConfigFileName {
+			if filepath.Dir(p) == r {
+				return r, true
+			}
+			continue
+		}
+		if strings.HasPrefix(p, strings.TrimSuffix(r, string(filepath.Separator))+string(filepath.Separator)) {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// isConfigFile returns true when the file changes the loaded files. e.g. .regols.yaml, .gitignore
+func isConfigFile(uri lsp.DocumentURI) bool {
+	name := path.Base(string(uri))
+	return name == source.ConfigFileName || slices.Contains(source.IgnoreFileNames, name)
+}
+
+func isRegoFile(uri lsp.DocumentURI) bool {
+	return strings.HasSuffix(string(uri), ".rego")
+}
+
+func isDataFile(uri lsp.DocumentURI) bool {
+	return source.IsDataFile(documentURIToURI(uri))
+}